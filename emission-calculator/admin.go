@@ -0,0 +1,235 @@
+package main
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// --------------------- Access control ---------------------
+
+// adminToken is required to change the registry; when empty, the registry is read-only.
+var adminToken string
+
+// adminCookie holds the token after logging in on the admin page.
+const adminCookie = "admin_token"
+
+// isAdmin reports whether the request carries the admin token, either as
+// "Authorization: Bearer <token>" (API clients) or in the login cookie (admin page).
+func isAdmin(r *http.Request) bool {
+	if adminToken == "" {
+		return false
+	}
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		c, err := r.Cookie(adminCookie)
+		if err != nil {
+			return false
+		}
+		token = c.Value
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1
+}
+
+// sameOrigin rejects cross-site browser requests: the login cookie is SameSite=Strict,
+// and a request that states its origin must come from this host.
+func sameOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" {
+		return true
+	}
+	u, err := url.Parse(origin)
+	return err == nil && u.Host == r.Host
+}
+
+// requireAdmin lets only requests with the admin token through to h.
+func requireAdmin(h http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case adminToken == "":
+			http.Error(w, "Редагування реєстру вимкнено: задайте EMISSION_ADMIN_TOKEN", http.StatusForbidden)
+		case !sameOrigin(r):
+			http.Error(w, "Запит з іншого сайту відхилено", http.StatusForbidden)
+		case !isAdmin(r):
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "Потрібен токен адміністратора", http.StatusUnauthorized)
+		default:
+			h(w, r)
+		}
+	}
+}
+
+// --------------------- Admin page ---------------------
+
+// adminHandler serves the registry editor (admin.html); the forms are read-only until logged in.
+func adminHandler(w http.ResponseWriter, r *http.Request) {
+	data := pageData()
+	data.History = registry.History()
+	data.Editing = adminToken != ""
+	data.Admin = isAdmin(r)
+	render(w, "admin.html", data)
+}
+
+// adminLoginHandler checks the token from the admin page and keeps it in a cookie.
+func adminLoginHandler(w http.ResponseWriter, r *http.Request) {
+	token := r.FormValue("token")
+	if adminToken == "" || !sameOrigin(r) || subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) != 1 {
+		http.Error(w, "Невірний токен адміністратора", http.StatusUnauthorized)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     adminCookie,
+		Value:    token,
+		Path:     "/",
+		HttpOnly: true,
+		SameSite: http.SameSiteStrictMode,
+	})
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// adminLogoutHandler drops the login cookie.
+func adminLogoutHandler(w http.ResponseWriter, r *http.Request) {
+	http.SetCookie(w, &http.Cookie{Name: adminCookie, Path: "/", MaxAge: -1})
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// parseFuelForm reads a complete fuel from the admin form; every coefficient is required.
+func parseFuelForm(r *http.Request) (FuelParams, error) {
+	f := FuelParams{ID: strings.TrimSpace(r.FormValue("id")), Name: strings.TrimSpace(r.FormValue("name")), Gas: r.FormValue("gas") != ""}
+	_, err := parseCoefficients(r, "", &f, true)
+	return f, err
+}
+
+// adminCreateHandler adds a fuel from the admin form.
+func adminCreateHandler(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFuelForm(r)
+	if err == nil {
+		err = registry.Create(f)
+	}
+	adminRedirect(w, r, err)
+}
+
+// adminUpdateHandler saves the edited coefficients of a fuel.
+func adminUpdateHandler(w http.ResponseWriter, r *http.Request) {
+	f, err := parseFuelForm(r)
+	if err == nil {
+		err = registry.Update(r.PathValue("id"), f)
+	}
+	adminRedirect(w, r, err)
+}
+
+// adminDeleteHandler removes a fuel.
+func adminDeleteHandler(w http.ResponseWriter, r *http.Request) {
+	adminRedirect(w, r, registry.Delete(r.PathValue("id")))
+}
+
+// adminRedirect returns to the admin page, or reports the error.
+func adminRedirect(w http.ResponseWriter, r *http.Request, err error) {
+	if err != nil {
+		writeRegistryError(w, err)
+		return
+	}
+	http.Redirect(w, r, "/admin", http.StatusSeeOther)
+}
+
+// --------------------- JSON API ---------------------
+
+// writeRegistryError responds with the status matching a registry error.
+func writeRegistryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, ErrFuelNotFound), errors.Is(err, ErrVersionNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, ErrFuelExists):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.As(err, new(ValidationError)):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// writeJSON sends v as JSON with the given status.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// decodeFuel reads a fuel from the request body; on failure it responds and returns false.
+func decodeFuel(w http.ResponseWriter, r *http.Request, f *FuelParams) bool {
+	if err := json.NewDecoder(r.Body).Decode(f); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// apiListFuels - GET /api/fuels, or GET /api/fuels?version=N for an earlier version
+func apiListFuels(w http.ResponseWriter, r *http.Request) {
+	version := 0
+	if v := r.URL.Query().Get("version"); v != "" {
+		var err error
+		if version, err = strconv.Atoi(v); err != nil || version < 1 {
+			http.Error(w, "Невірна версія реєстру", http.StatusBadRequest)
+			return
+		}
+	}
+	s, err := registry.Snapshot(version)
+	if err != nil {
+		writeRegistryError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, s)
+}
+
+// apiGetFuel - GET /api/fuels/{id}
+func apiGetFuel(w http.ResponseWriter, r *http.Request) {
+	f, err := registry.Get(r.PathValue("id"))
+	if err != nil {
+		writeRegistryError(w, err)
+		return
+	}
+	writeJSON(w, http.StatusOK, f)
+}
+
+// apiCreateFuel - POST /api/fuels
+func apiCreateFuel(w http.ResponseWriter, r *http.Request) {
+	var f FuelParams
+	if !decodeFuel(w, r, &f) {
+		return
+	}
+	if err := registry.Create(f); err != nil {
+		writeRegistryError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/fuels/"+f.ID)
+	writeJSON(w, http.StatusCreated, f)
+}
+
+// apiUpdateFuel - PUT /api/fuels/{id}
+func apiUpdateFuel(w http.ResponseWriter, r *http.Request) {
+	var f FuelParams
+	if !decodeFuel(w, r, &f) {
+		return
+	}
+	id := r.PathValue("id")
+	if err := registry.Update(id, f); err != nil {
+		writeRegistryError(w, err)
+		return
+	}
+	f.ID = id
+	writeJSON(w, http.StatusOK, f)
+}
+
+// apiDeleteFuel - DELETE /api/fuels/{id}
+func apiDeleteFuel(w http.ResponseWriter, r *http.Request) {
+	if err := registry.Delete(r.PathValue("id")); err != nil {
+		writeRegistryError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+const testFuel = `{"id":"peat","name":"Торф","qri":8.12,"a":0.5,"ar":6,"g":1,"n":0.9,"ks":0,"alpha":1,
+	"s":0.3,"etaSO2":0,"c":24,"nox":200,"q3":0.5,"q4":2,"r":1}`
+
+// serve sends a request to the application routes.
+func serve(r *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	routes().ServeHTTP(w, r)
+	return w
+}
+
+func postForm(target string, form url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestMutationsRequireAdminToken(t *testing.T) {
+	tests := []struct {
+		name    string
+		token   string // Configured admin token
+		request func() *http.Request
+		want    int
+	}{
+		{"editing disabled", "", func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "/api/fuels", strings.NewReader(testFuel))
+			r.Header.Set("Authorization", "Bearer ")
+			return r
+		}, http.StatusForbidden},
+		{"no token", "secret", func() *http.Request {
+			return httptest.NewRequest(http.MethodDelete, "/api/fuels/gas", nil)
+		}, http.StatusUnauthorized},
+		{"wrong token", "secret", func() *http.Request {
+			r := httptest.NewRequest(http.MethodDelete, "/api/fuels/gas", nil)
+			r.Header.Set("Authorization", "Bearer guess")
+			return r
+		}, http.StatusUnauthorized},
+		{"API token", "secret", func() *http.Request {
+			r := httptest.NewRequest(http.MethodPost, "/api/fuels", strings.NewReader(testFuel))
+			r.Header.Set("Authorization", "Bearer secret")
+			return r
+		}, http.StatusCreated},
+		{"admin form without login", "secret", func() *http.Request {
+			return postForm("/admin/fuels/gas/delete", nil)
+		}, http.StatusUnauthorized},
+		{"admin form with login cookie", "secret", func() *http.Request {
+			r := postForm("/admin/fuels/gas/delete", nil)
+			r.AddCookie(&http.Cookie{Name: adminCookie, Value: "secret"})
+			return r
+		}, http.StatusSeeOther},
+		{"cross-site form", "secret", func() *http.Request {
+			r := postForm("/admin/fuels/gas/delete", nil)
+			r.AddCookie(&http.Cookie{Name: adminCookie, Value: "secret"})
+			r.Header.Set("Origin", "https://evil.example")
+			return r
+		}, http.StatusForbidden},
+		{"reading stays open", "", func() *http.Request {
+			return httptest.NewRequest(http.MethodGet, "/api/fuels/gas", nil)
+		}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		registry = openTestRegistry(t)
+		adminToken = tt.token
+		if w := serve(tt.request()); w.Code != tt.want {
+			t.Errorf("%s: status %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+	adminToken = ""
+}
+
+func TestAdminLogin(t *testing.T) {
+	registry = openTestRegistry(t)
+	adminToken = "secret"
+	defer func() { adminToken = "" }()
+
+	if w := serve(postForm("/admin/login", url.Values{"token": {"guess"}})); w.Code != http.StatusUnauthorized {
+		t.Errorf("wrong token: status %d", w.Code)
+	}
+	w := serve(postForm("/admin/login", url.Values{"token": {"secret"}}))
+	cookies := w.Result().Cookies()
+	if w.Code != http.StatusSeeOther || len(cookies) != 1 || cookies[0].SameSite != http.SameSiteStrictMode || !cookies[0].HttpOnly {
+		t.Fatalf("login: status %d, cookies %+v", w.Code, cookies)
+	}
+
+	r := httptest.NewRequest(http.MethodGet, "/admin", nil)
+	r.AddCookie(cookies[0])
+	if body := serve(r).Body.String(); !strings.Contains(body, "Вийти з режиму редагування") || strings.Contains(body, "disabled") {
+		t.Error("admin page is not editable after login")
+	}
+	if body := serve(httptest.NewRequest(http.MethodGet, "/admin", nil)).Body.String(); !strings.Contains(body, "Увійти для редагування") {
+		t.Error("admin page without login offers no login form")
+	}
+}
+
+func TestCalculateWithEarlierVersion(t *testing.T) {
+	registry = openTestRegistry(t)
+	coal, _ := registry.Get("coal")
+	coal.N = 0.99
+	if err := registry.Update("coal", coal); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		version string
+		want    []string
+	}{
+		{"", []string{"<strong>K:</strong> 99.985", `version=3">3</a>`}},
+		{"2", []string{"<strong>K:</strong> 149.978", `version=2">2</a> (не поточна)`}},
+		{"1", []string{"<strong>K:</strong> 149.978", `version=1">1</a> (не поточна)`}},
+	}
+	for _, tt := range tests {
+		form := url.Values{"fuelType": {"coal"}, "fuelAmount": {"1000"}, "version": {tt.version}}
+		body := serve(postForm("/calculate", form)).Body.String()
+		for _, want := range tt.want {
+			if !strings.Contains(body, want) {
+				t.Errorf("version %q: result lacks %q", tt.version, want)
+			}
+		}
+	}
+	if w := serve(postForm("/calculate", url.Values{"fuelType": {"coal"}, "fuelAmount": {"1000"}, "version": {"9"}})); w.Code != http.StatusBadRequest {
+		t.Errorf("unknown version: status %d", w.Code)
+	}
+	if w := serve(httptest.NewRequest(http.MethodGet, "/api/fuels?version=2", nil)); !strings.Contains(w.Body.String(), `"n":0.985`) {
+		t.Errorf("GET /api/fuels?version=2: %s", w.Body)
+	}
+}
+
+func TestCalculateRejectsNonFinite(t *testing.T) {
+	registry = openTestRegistry(t)
+	for _, field := range []url.Values{
+		{"override_qri": {"NaN"}},
+		{"override_a": {"nan"}},
+		{"override_n": {"+Inf"}},
+		{"override_c": {"-Inf"}},
+		{"fuelAmount": {"NaN"}},
+		{"fuelAmount": {"Inf"}},
+	} {
+		form := url.Values{"fuelType": {"coal"}, "fuelAmount": {"1000"}}
+		for k, v := range field {
+			form[k] = v
+		}
+		if w := serve(postForm("/calculate", form)); w.Code != http.StatusBadRequest {
+			t.Errorf("%v: status %d, want 400", field, w.Code)
+		}
+	}
+}
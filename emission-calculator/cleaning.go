@@ -0,0 +1,107 @@
+package main
+
+import "net/http"
+
+// Maximum number of gas-cleaning stages on the form
+const maxStages = 4
+
+// Efficiency is the capture efficiency of gas cleaning per pollutant, fraction.
+// CO2 is not captured by any of the equipment.
+type Efficiency struct {
+	Particulates float64
+	SO2          float64
+	NOx          float64
+	CO           float64
+}
+
+// Equipment is one kind of gas-cleaning equipment in the catalog.
+type Equipment struct {
+	ID       string
+	Name     string
+	Nominal  Efficiency // Design efficiency
+	Degraded Efficiency // Worn or partly failed equipment (e.g. an ESP field out of service)
+}
+
+// equipmentCatalog lists the gas-cleaning equipment offered on the form.
+var equipmentCatalog = []Equipment{
+	{"esp", "Електрофільтр", Efficiency{0.99, 0, 0, 0}, Efficiency{0.95, 0, 0, 0}},
+	{"cyclones", "Батарейні циклони", Efficiency{0.85, 0, 0, 0}, Efficiency{0.75, 0, 0, 0}},
+	{"bagFilter", "Рукавний фільтр", Efficiency{0.998, 0, 0, 0}, Efficiency{0.98, 0, 0, 0}},
+	{"wetScrubber", "Мокрий скрубер (Вентурі)", Efficiency{0.96, 0.3, 0, 0}, Efficiency{0.9, 0.15, 0, 0}},
+	{"fgd", "Сіркоочищення (мокре вапнякове)", Efficiency{0.5, 0.95, 0, 0}, Efficiency{0.3, 0.8, 0, 0}},
+	{"sncr", "Некаталітичне відновлення NOx (SNCR)", Efficiency{0, 0, 0.4, 0}, Efficiency{0, 0, 0.25, 0}},
+	{"scr", "Каталітичне відновлення NOx (SCR)", Efficiency{0, 0, 0.85, 0}, Efficiency{0, 0, 0.6, 0}},
+	{"oxidationCatalyst", "Каталізатор окиснення CO", Efficiency{0, 0, 0, 0.9}, Efficiency{0, 0, 0, 0.6}},
+}
+
+// GasCleaning is a chain of gas-cleaning stages; the flue gas passes them in order.
+type GasCleaning struct {
+	Stages []Equipment
+}
+
+// combine returns the efficiency of the chain of stages.
+func (g GasCleaning) combine(stage func(Equipment) Efficiency) Efficiency {
+	eta := Efficiency{}
+	for _, e := range g.Stages {
+		eta = eta.then(stage(e))
+	}
+	return eta
+}
+
+// then returns the efficiency of eta followed by next.
+func (eta Efficiency) then(next Efficiency) Efficiency {
+	return Efficiency{
+		Particulates: inSeries(eta.Particulates, next.Particulates),
+		SO2:          inSeries(eta.SO2, next.SO2),
+		NOx:          inSeries(eta.NOx, next.NOx),
+		CO:           inSeries(eta.CO, next.CO),
+	}
+}
+
+// inSeries returns the efficiency of two captures in series: the penetrations 1 - η multiply.
+func inSeries(a, b float64) float64 {
+	return 1 - (1-a)*(1-b)
+}
+
+// Nominal returns the combined design efficiency of the chain.
+func (g GasCleaning) Nominal() Efficiency {
+	return g.combine(func(e Equipment) Efficiency { return e.Nominal })
+}
+
+// Degraded returns the combined efficiency with every stage degraded.
+func (g GasCleaning) Degraded() Efficiency {
+	return g.combine(func(e Equipment) Efficiency { return e.Degraded })
+}
+
+// Apply adds the chain efficiency eta after the capture already set in f and c
+// (e.g. a manually entered N or desulfurization efficiency).
+func (eta Efficiency) Apply(f *FuelParams, c *Conditions) {
+	f.N = inSeries(f.N, eta.Particulates)
+	c.Desulfurization = inSeries(c.Desulfurization, eta.SO2)
+	c.DeNOx = inSeries(c.DeNOx, eta.NOx)
+	c.COOxidation = inSeries(c.COOxidation, eta.CO)
+}
+
+// parseGasCleaning reads the selected stages (form fields "stage", in order; empty ones are skipped).
+func parseGasCleaning(r *http.Request) (GasCleaning, error) {
+	var g GasCleaning
+	for _, id := range r.Form["stage"] {
+		if id == "" {
+			continue
+		}
+		found := false
+		for _, e := range equipmentCatalog {
+			if e.ID == id {
+				g.Stages = append(g.Stages, e)
+				found = true
+			}
+		}
+		if !found {
+			return g, ValidationError("Невірно обрано обладнання газоочищення")
+		}
+	}
+	if len(g.Stages) > maxStages {
+		return g, ValidationError("Забагато ступенів газоочищення")
+	}
+	return g, nil
+}
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+// stages picks equipment from the catalog by ID.
+func stages(t *testing.T, ids ...string) GasCleaning {
+	t.Helper()
+	var g GasCleaning
+	for _, id := range ids {
+		found := false
+		for _, e := range equipmentCatalog {
+			if e.ID == id {
+				g.Stages, found = append(g.Stages, e), true
+			}
+		}
+		if !found {
+			t.Fatalf("no equipment %q", id)
+		}
+	}
+	return g
+}
+
+func TestGasCleaningChain(t *testing.T) {
+	tests := []struct {
+		stages            []string
+		nominal, degraded Efficiency
+	}{
+		{nil, Efficiency{}, Efficiency{}},
+		{[]string{"esp"}, Efficiency{0.99, 0, 0, 0}, Efficiency{0.95, 0, 0, 0}},
+		{[]string{"esp", "fgd"}, Efficiency{0.995, 0.95, 0, 0}, Efficiency{0.965, 0.8, 0, 0}},
+		{[]string{"cyclones", "esp"}, Efficiency{0.9985, 0, 0, 0}, Efficiency{0.9875, 0, 0, 0}},
+		{[]string{"sncr", "scr", "oxidationCatalyst"}, Efficiency{0, 0, 0.91, 0.9}, Efficiency{0, 0, 0.7, 0.6}},
+	}
+	for _, tt := range tests {
+		g := stages(t, tt.stages...)
+		name := strings.Join(tt.stages, "+")
+		for _, c := range []struct {
+			label     string
+			got, want Efficiency
+		}{{"nominal", g.Nominal(), tt.nominal}, {"degraded", g.Degraded(), tt.degraded}} {
+			approx(t, name+" "+c.label+" particulates", c.got.Particulates, c.want.Particulates, 1e-12)
+			approx(t, name+" "+c.label+" SO2", c.got.SO2, c.want.SO2, 1e-12)
+			approx(t, name+" "+c.label+" NOx", c.got.NOx, c.want.NOx, 1e-12)
+			approx(t, name+" "+c.label+" CO", c.got.CO, c.want.CO, 1e-12)
+		}
+	}
+}
+
+func TestApplyCombinesManualCapture(t *testing.T) {
+	f := FuelParams{N: 0.9}
+	c := Conditions{Desulfurization: 0.5}
+	stages(t, "esp", "fgd", "scr").Nominal().Apply(&f, &c)
+	approx(t, "N", f.N, 0.9995, 1e-12) // 1 − 0.1 · 0.005
+	approx(t, "desulfurization", c.Desulfurization, 0.975, 1e-12)
+	approx(t, "DeNOx", c.DeNOx, 0.85, 1e-12)
+	approx(t, "CO oxidation", c.COOxidation, 0, 1e-12)
+}
+
+func TestCalculateWithGasCleaning(t *testing.T) {
+	registry = openTestRegistry(t)
+	tests := []struct {
+		name string
+		form url.Values
+		want []string
+	}{
+		// The chain replaces the registry N (0.985): K = 149.978 · 0.01 / 0.015
+		{"chain only", url.Values{"stage": {"esp"}},
+			[]string{"<strong>K:</strong> 99.985", "газоочищення (замість реєстру)"}},
+		// A manual N is kept as a stage in front of the chain: N = 1 − 0.1 · 0.01
+		{"manual N and chain", url.Values{"stage": {"esp"}, "override_n": {"0.9"}},
+			[]string{"<td>0.999</td>", "введено вручну + газоочищення"}},
+		// Desulfurization 50 % then FGD 95 %: 97.5 %
+		{"desulfurization and FGD", url.Values{"stage": {"fgd"}, "desulfurization": {"50"}},
+			[]string{"ефективність сіркоочищення 97.5 %", "<td>1.283</td>"}},
+		{"SCR", url.Values{"stage": {"scr"}}, []string{"<td>0.768</td>"}},
+	}
+	for _, tt := range tests {
+		tt.form.Set("fuelType", "coal")
+		tt.form.Set("fuelAmount", "1000")
+		body := serve(postForm("/calculate", tt.form)).Body.String()
+		for _, want := range tt.want {
+			if !strings.Contains(body, want) {
+				t.Errorf("%s: result lacks %q", tt.name, want)
+			}
+		}
+	}
+}
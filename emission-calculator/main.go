@@ -1,37 +1,145 @@
 package main
 
 import (
-	"fmt"
 	"html/template"
 	"log"
 	"math"
 	"net/http"
+	"os"
 	"strconv"
+	"strings"
 )
 
+// Data file of the fuel parameter registry and the tracked seed it starts from
+const registryPath, registrySeed = "data/fuels.json", "data/fuels.default.json"
+
+// registry holds the editable fuel parameters.
+var registry *Registry
+
 // --- A simple struct to hold the result for the template ---
 type ResultData struct {
-	FuelType string
-	KValue   float64
-	EValue   float64
+	FuelType     string
+	KValue       float64
+	EValue       float64
+	Fuel         FuelParams // Coefficients actually used, after the overrides
+	Amount       float64
+	Overridden   map[string]bool // Coefficients entered on the form instead of taken from the registry
+	Coefficients []Coefficient
+	Version      int  // Registry version the calculation was based on
+	Current      bool // Version is still the current one
+	Conditions   Conditions
+	Pollutants   []PollutantEmission
+	Cleaning     GasCleaning
+	Degraded     []PollutantEmission // Pollutants with degraded gas cleaning; nil without stages
+}
+
+// PageData is passed to the form and admin templates.
+type PageData struct {
+	Fuels        []FuelParams
+	Coefficients []Coefficient
+	Boilers      []BoilerType
+	Equipment    []Equipment
+	MaxStages    int
+	Version      int
+	History      []Snapshot // Earlier registry versions, newest first
+	Editing      bool       // Changes are enabled (an admin token is configured)
+	Admin        bool       // The request carries the admin token
 }
 
 func main() {
-	http.HandleFunc("/", indexHandler)
-	http.HandleFunc("/calculate", calculateHandler)
+	var err error
+	registry, err = OpenRegistry(registryPath, registrySeed)
+	if err != nil {
+		log.Fatal(err)
+	}
+	adminToken = os.Getenv("EMISSION_ADMIN_TOKEN")
+	if adminToken == "" {
+		log.Println("EMISSION_ADMIN_TOKEN is not set: the fuel registry is read-only")
+	}
 
 	log.Println("Server running on http://localhost:8080/")
-	log.Fatal(http.ListenAndServe(":8080", nil))
+	log.Fatal(http.ListenAndServe(":8080", routes()))
 }
 
-// indexHandler serves the form (index.html).
-func indexHandler(w http.ResponseWriter, r *http.Request) {
-	tmpl, err := template.ParseFiles("templates/index.html")
+// routes registers the handlers of the calculator, the admin page and the JSON API.
+func routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", indexHandler)
+	mux.HandleFunc("/calculate", calculateHandler)
+
+	// Changes to the registry require the admin token (see requireAdmin)
+	mux.HandleFunc("GET /admin", adminHandler)
+	mux.HandleFunc("POST /admin/login", adminLoginHandler)
+	mux.HandleFunc("POST /admin/logout", adminLogoutHandler)
+	mux.HandleFunc("POST /admin/fuels", requireAdmin(adminCreateHandler))
+	mux.HandleFunc("POST /admin/fuels/{id}", requireAdmin(adminUpdateHandler))
+	mux.HandleFunc("POST /admin/fuels/{id}/delete", requireAdmin(adminDeleteHandler))
+
+	mux.HandleFunc("GET /api/fuels", apiListFuels)
+	mux.HandleFunc("POST /api/fuels", requireAdmin(apiCreateFuel))
+	mux.HandleFunc("GET /api/fuels/{id}", apiGetFuel)
+	mux.HandleFunc("PUT /api/fuels/{id}", requireAdmin(apiUpdateFuel))
+	mux.HandleFunc("DELETE /api/fuels/{id}", requireAdmin(apiDeleteFuel))
+
+	return mux
+}
+
+// render executes the named template from the templates directory.
+func render(w http.ResponseWriter, name string, data any) {
+	tmpl, err := template.ParseFiles("templates/" + name)
 	if err != nil {
 		http.Error(w, "Помилка завантаження сторінки", http.StatusInternalServerError)
 		return
 	}
-	tmpl.Execute(w, nil)
+	tmpl.Execute(w, data)
+}
+
+// pageData collects the registry contents for the templates.
+func pageData() PageData {
+	current, _ := registry.Snapshot(0)
+	return PageData{
+		Fuels:        current.Fuels,
+		Coefficients: coefficients,
+		Boilers:      boilerTypes,
+		Equipment:    equipmentCatalog,
+		MaxStages:    maxStages,
+		Version:      current.Version,
+	}
+}
+
+// indexHandler serves the form (index.html).
+func indexHandler(w http.ResponseWriter, r *http.Request) {
+	render(w, "index.html", pageData())
+}
+
+// parseCoefficients fills the coefficients of f from the form fields prefix+key.
+// Empty fields are left unchanged unless required is set; the keys that were set are returned.
+// NaN and ±Inf are rejected: ParseFloat accepts them, but no range check in Validate catches NaN.
+func parseCoefficients(r *http.Request, prefix string, f *FuelParams, required bool) (map[string]bool, error) {
+	set := map[string]bool{}
+	for _, c := range coefficients {
+		s := strings.TrimSpace(r.FormValue(prefix + c.Key))
+		if s == "" && !required {
+			continue
+		}
+		v, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+		if err != nil || math.IsNaN(v) || math.IsInf(v, 0) {
+			return nil, ValidationError("Невірне значення " + c.Label)
+		}
+		*f.Field(c.Key) = v
+		set[c.Key] = true
+	}
+	return set, nil
+}
+
+// calculateEmission returns the emission factor K (g/GJ) and the gross emission E (t) for amount tonnes of fuel.
+func calculateEmission(f FuelParams, amount float64) (K, E float64) {
+	// K = (10^6 / Qri) * A * alpha * (Ar/(100 - G)) * (1 - N) + Ks
+	K = (math.Pow(10, 6)/f.Qri)*f.A*f.Alpha*(f.Ar/(100-f.G))*(1-f.N) + f.Ks
+
+	// E = 10^(-6) * K * Qri * B
+	E = math.Pow(10, -6) * K * f.Qri * amount
+	return K, E
 }
 
 // calculateHandler reads form data, does the emission math, and returns a result page.
@@ -49,73 +157,84 @@ func calculateHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// 2) Extract the fuel type and amount from the form
-	fuelType := r.FormValue("fuelType") // "coal", "oilFuel", or "gas"
+	fuelType := r.FormValue("fuelType") // ID of a fuel in the registry
 	amountStr := r.FormValue("fuelAmount")
 	amount, err := strconv.ParseFloat(amountStr, 64)
-	if err != nil || amount <= 0 {
+	if err != nil || !(amount > 0) || math.IsInf(amount, 0) {
 		http.Error(w, "Невірне значення кількості палива", http.StatusBadRequest)
 		return
 	}
 
-	// 3) We'll store default parameters for each fuel right here
-	//    These mirror the logic from your previous JSON-based approach
-	//    but are now handled directly in Go.
-	var Qri, A, Ar, G, N, Ks, alpha float64
-
-	switch fuelType {
-	case "coal":
-		Qri = 20.47
-		A = 1.0
-		Ar = 25.2
-		G = 1.5
-		N = 0.985
-		Ks = 0
-		alpha = 0.8
-	case "oilFuel":
-		Qri = 40.40
-		A = 1.0
-		Ar = 0.15
-		G = 0
-		N = 0.985
-		Ks = 0
-		alpha = 1.0
-	case "gas":
-		Qri = 33.08
-		A = 0
-		Ar = 0
-		G = 0
-		N = 0
-		Ks = 0
-	default:
+	// 3) Take the fuel parameters from the registry (the current or a requested earlier version)
+	// and apply the per-calculation overrides
+	version := 0
+	if v := strings.TrimSpace(r.FormValue("version")); v != "" {
+		version, err = strconv.Atoi(v)
+		if err != nil || version < 1 {
+			http.Error(w, "Невірна версія реєстру", http.StatusBadRequest)
+			return
+		}
+	}
+	snapshot, err := registry.Snapshot(version)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	fuel, err := snapshot.Fuel(fuelType)
+	if err != nil {
 		http.Error(w, "Невірно обрано паливо", http.StatusBadRequest)
 		return
 	}
+	overridden, err := parseCoefficients(r, "override_", &fuel, false)
+	if err == nil {
+		err = fuel.Validate()
+	}
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	conditions, err := parseConditions(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// 4) The gas-cleaning chain, if any, follows the capture entered on the form: a manual N
+	// and the desulfurization efficiency combine with it, while the registry N is replaced by it
+	cleaning, err := parseGasCleaning(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	var degraded []PollutantEmission
+	if len(cleaning.Stages) > 0 {
+		if !overridden["n"] {
+			fuel.N = 0
+		}
+		worn, wornConditions := fuel, conditions
+		cleaning.Degraded().Apply(&worn, &wornConditions)
+		degraded = calculateInventory(worn, amount, wornConditions)
+		cleaning.Nominal().Apply(&fuel, &conditions)
+	}
+
+	// 5) Calculate K and E, and the emissions of the other pollutants
+	KValue, EValue := calculateEmission(fuel, amount)
+	pollutants := calculateInventory(fuel, amount, conditions)
 
-	// 4) Calculate K and E using your formulas
-	//    K = (10^6 / Qri) * A * (Ar/(100 - G)) * (1 - N) + Ks
-	KValue := (math.Pow(10, 6)/Qri)*A*alpha*(Ar/(100-G))*(1-N) + Ks
-
-	//    E = 10^(-6) * K * Qri * B
-	EValue := math.Pow(10, -6) * KValue * Qri * amount
-
-	// 5) Construct a simple HTML response OR use a separate template
-	//    For simplicity, let's just build a small HTML right here
-	//    Or you could parse a "templates/result.html" if you prefer
-	fmt.Fprintf(w, `
-<!DOCTYPE html>
-<html lang="uk">
-<head>
-  <meta charset="UTF-8"/>
-  <title>Результат розрахунку</title>
-</head>
-<body style="font-family: sans-serif; margin: 2rem;">
-  <h1>Результати</h1>
-  <p><strong>Тип палива:</strong> %s</p>
-  <p><strong>K:</strong> %.3f</p>
-  <p><strong>E:</strong> %.3f</p>
-  <hr>
-  <a href="/">Повернутися назад</a>
-</body>
-</html>
-`, fuelType, KValue, EValue)
+	// 6) Render the result page
+	render(w, "result.html", ResultData{
+		FuelType:     fuel.Name,
+		KValue:       KValue,
+		EValue:       EValue,
+		Fuel:         fuel,
+		Amount:       amount,
+		Overridden:   overridden,
+		Coefficients: coefficients,
+		Version:      snapshot.Version,
+		Current:      snapshot.Version == registry.Version(),
+		Conditions:   conditions,
+		Pollutants:   pollutants,
+		Cleaning:     cleaning,
+		Degraded:     degraded,
+	})
 }
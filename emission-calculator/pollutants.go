@@ -0,0 +1,123 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// BoilerType scales the NOx emission of the reference boiler.
+type BoilerType struct {
+	ID           string
+	Name         string
+	NOxFactor    float64 // Ratio to the reference boiler at nominal load
+	LoadExponent float64 // NOx emission factor grows as load^LoadExponent
+}
+
+// boilerTypes lists the boilers offered on the form; the first one is the reference.
+var boilerTypes = []BoilerType{
+	{"pulverizedDry", "Пиловугільний, тверде шлаковидалення", 1.0, 0.5},
+	{"pulverizedWet", "Пиловугільний, рідке шлаковидалення", 1.4, 0.5},
+	{"gasOil", "Газомазутний", 1.0, 1.0},
+	{"fluidizedBed", "Циркулюючий киплячий шар", 0.4, 0.5},
+	{"stoker", "Шаровий (колосникові ґрати)", 0.7, 0.5},
+}
+
+// Conditions are the boiler operating conditions of a calculation.
+type Conditions struct {
+	Boiler          BoilerType
+	Load            float64 // Fraction of the nominal load
+	Desulfurization float64 // Efficiency of the flue gas desulfurization, fraction
+	DeNOx           float64 // NOx reduction by the gas cleaning (SNCR/SCR), fraction
+	COOxidation     float64 // CO oxidised by the gas cleaning, fraction
+}
+
+// LoadPercent returns the load in % of the nominal load.
+func (c Conditions) LoadPercent() float64 { return c.Load * 100 }
+
+// DesulfurizationPercent returns the desulfurization efficiency in %.
+func (c Conditions) DesulfurizationPercent() float64 { return c.Desulfurization * 100 }
+
+// parseConditions reads the boiler type, load (%) and desulfurization efficiency (%) from the form.
+func parseConditions(r *http.Request) (Conditions, error) {
+	c := Conditions{Boiler: boilerTypes[0], Load: 1}
+	if id := r.FormValue("boilerType"); id != "" {
+		found := false
+		for _, b := range boilerTypes {
+			if b.ID == id {
+				c.Boiler, found = b, true
+			}
+		}
+		if !found {
+			return c, ValidationError("Невірно обрано тип котла")
+		}
+	}
+	percent := func(name string, v *float64, min, max float64, msg string) error {
+		s := strings.TrimSpace(r.FormValue(name))
+		if s == "" {
+			return nil
+		}
+		x, err := strconv.ParseFloat(strings.ReplaceAll(s, ",", "."), 64)
+		// Written as !(in range) so that NaN, which fails every comparison, is rejected too
+		if err != nil || !(x >= min && x <= max) {
+			return ValidationError(msg)
+		}
+		*v = x / 100
+		return nil
+	}
+	if err := percent("boilerLoad", &c.Load, 1, 120, "Навантаження котла має бути в межах 1–120 %"); err != nil {
+		return c, err
+	}
+	if err := percent("desulfurization", &c.Desulfurization, 0, 100, "Ефективність сіркоочищення має бути в межах 0–100 %"); err != nil {
+		return c, err
+	}
+	return c, nil
+}
+
+// PollutantEmission is the emission factor and gross emission of one pollutant.
+type PollutantEmission struct {
+	Name    string
+	Formula string
+	K       float64 // Emission factor, g/GJ
+	E       float64 // Gross emission, t
+	PerTon  float64 // Emission per tonne of fuel, kg/t
+}
+
+// newEmission computes E from K the same way for every pollutant: E = 10^(-6) * K * Qri * B.
+func newEmission(name, formula string, K float64, f FuelParams, amount float64) PollutantEmission {
+	return PollutantEmission{
+		Name:    name,
+		Formula: formula,
+		K:       K,
+		E:       math.Pow(10, -6) * K * f.Qri * amount,
+		PerTon:  math.Pow(10, -3) * K * f.Qri,
+	}
+}
+
+// calculateInventory returns the emissions of every pollutant for amount tonnes of fuel.
+func calculateInventory(f FuelParams, amount float64, c Conditions) []PollutantEmission {
+	particulates, _ := calculateEmission(f, amount)
+
+	// Sulfur oxides (as SO2): sulfur burns to SO2 (2 g per g of S); part is bound by the ash
+	// and part is captured by the desulfurization
+	kSO2 := math.Pow(10, 6) / f.Qri * 2 * f.S / 100 * (1 - f.EtaSO2) * (1 - c.Desulfurization)
+
+	// Nitrogen oxides (as NO2): reference factor scaled by the boiler type and load, less the DeNOx reduction
+	kNOx := f.NOx * c.Boiler.NOxFactor * math.Pow(c.Load, c.Boiler.LoadExponent) * (1 - c.DeNOx)
+
+	// Carbon monoxide: C_CO = q3 * R * Qri g/kg, reduced by the unburnt fuel share q4 and the CO oxidation
+	kCO := math.Pow(10, 3) * f.Q3 * f.R * (1 - f.Q4/100) * (1 - c.COOxidation)
+
+	// Carbon dioxide: the burnt carbon oxidises to CO2 (44/12 g per g of C)
+	kCO2 := math.Pow(10, 6) / f.Qri * 44.0 / 12 * f.C / 100 * (1 - f.Q4/100)
+
+	return []PollutantEmission{
+		newEmission("Тверді частинки", "10⁶/Qri · A · alpha · Ar/(100 − G) · (1 − N) + Ks", particulates, f, amount),
+		newEmission("SO₂", fmt.Sprintf("10⁶/Qri · 2 · Sr/100 · (1 − ηSO2) · (1 − ηсо), ηсо = %.3g", c.Desulfurization), kSO2, f, amount),
+		newEmission("NOx (у перерахунку на NO₂)", fmt.Sprintf("kNOx · %.3g · %.3g^%.3g · (1 − ηNOx), ηNOx = %.3g", c.Boiler.NOxFactor, c.Load, c.Boiler.LoadExponent, c.DeNOx), kNOx, f, amount),
+		newEmission("CO", fmt.Sprintf("10³ · q3 · R · (1 − q4/100) · (1 − ηCO), ηCO = %.3g", c.COOxidation), kCO, f, amount),
+		newEmission("CO₂", "10⁶/Qri · 44/12 · Cr/100 · (1 − q4/100)", kCO2, f, amount),
+	}
+}
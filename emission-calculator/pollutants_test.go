@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func approx(t *testing.T, name string, got, want, tol float64) {
+	t.Helper()
+	if math.Abs(got-want) > tol {
+		t.Errorf("%s = %.6f, want %.6f", name, got, want)
+	}
+}
+
+func TestCalculateInventory(t *testing.T) {
+	reg := openTestRegistry(t)
+	coal, _ := reg.Get("coal")
+	gas, _ := reg.Get("gas")
+	reference := Conditions{Boiler: boilerTypes[0], Load: 1}
+
+	tests := []struct {
+		name   string
+		fuel   FuelParams
+		c      Conditions
+		perTon []float64 // kg/t: particulates, SO2, NOx, CO, CO2
+	}{
+		{"coal, reference boiler", coal, reference, []float64{3.070051, 51.3, 5.1175, 10.081475, 1895.763833}},
+		{"coal, wet-bottom boiler at 60 %", coal, Conditions{Boiler: boilerTypes[1], Load: 0.6},
+			[]float64{3.070051, 51.3, 5.549598, 10.081475, 1895.763833}},
+		{"coal, 50 % desulfurization", coal, Conditions{Boiler: boilerTypes[0], Load: 1, Desulfurization: 0.5},
+			[]float64{3.070051, 25.65, 5.1175, 10.081475, 1895.763833}},
+		{"coal, SCR and CO catalyst", coal, Conditions{Boiler: boilerTypes[0], Load: 1, DeNOx: 0.85, COOxidation: 0.9},
+			[]float64{3.070051, 51.3, 0.767625, 1.0081475, 1895.763833}},
+		{"gas", gas, Conditions{Boiler: boilerTypes[2], Load: 1}, []float64{0, 0, 2.9772, 3.308, 1855.333333}},
+	}
+	for _, tt := range tests {
+		inventory := calculateInventory(tt.fuel, 1000, tt.c)
+		if len(inventory) != len(tt.perTon) {
+			t.Fatalf("%s: %d pollutants", tt.name, len(inventory))
+		}
+		for i, p := range inventory {
+			approx(t, tt.name+" "+p.Name+" kg/t", p.PerTon, tt.perTon[i], 1e-5)
+			approx(t, tt.name+" "+p.Name+" E", p.E, tt.perTon[i], 1e-5) // 1000 t: E in t equals kg/t
+		}
+	}
+}
+
+func TestCalculateEmission(t *testing.T) {
+	reg := openTestRegistry(t)
+	coal, _ := reg.Get("coal")
+	K, E := calculateEmission(coal, 100)
+	approx(t, "K", K, 149.978054, 1e-5)
+	approx(t, "E", E, 0.307005, 1e-6)
+}
+
+func TestGasIsPerCubicMetre(t *testing.T) {
+	registry = openTestRegistry(t)
+	gas, _ := registry.Get("gas")
+	if !gas.Gas {
+		t.Fatal("seed gas is not marked as per m³")
+	}
+	// C is kg of carbon per 100 m³: 0.506 kg/m³ at 33.08 MJ/m³ gives the IPCC 56.1 t CO2/TJ
+	co2 := calculateInventory(gas, 1000, Conditions{Boiler: boilerTypes[2], Load: 1})[4]
+	approx(t, "CO2 k", co2.K, 56086.255542, 1e-5)
+
+	body := serve(postForm("/calculate", url.Values{"fuelType": {"gas"}, "fuelAmount": {"10"}})).Body.String()
+	for _, want := range []string{"10.000 тис. м³", "кг/тис. м³"} {
+		if !strings.Contains(body, want) {
+			t.Errorf("gas result lacks %q", want)
+		}
+	}
+}
+
+func TestParseConditionsRejectsNonFinite(t *testing.T) {
+	tests := []struct {
+		form    url.Values
+		wantErr bool
+	}{
+		{url.Values{"boilerLoad": {"80"}, "desulfurization": {"50,5"}}, false},
+		{url.Values{"boilerLoad": {"NaN"}}, true},
+		{url.Values{"boilerLoad": {"+Inf"}}, true},
+		{url.Values{"desulfurization": {"nan"}}, true},
+		{url.Values{"desulfurization": {"-Inf"}}, true},
+	}
+	for _, tt := range tests {
+		c, err := parseConditions(postForm("/calculate", tt.form))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: conditions %+v, error %v", tt.form, c, err)
+		}
+	}
+}
@@ -0,0 +1,344 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FuelParams holds the emission coefficients of one fuel.
+type FuelParams struct {
+	ID    string  `json:"id"`
+	Name  string  `json:"name"`
+	Qri   float64 `json:"qri"`   // Lower heating value, MJ/kg (gas: MJ/m³)
+	A     float64 `json:"a"`     // Share of ash carried away with the flue gas (a_vin)
+	Ar    float64 `json:"ar"`    // Ash content of the working mass, %
+	G     float64 `json:"g"`     // Combustibles in the carried-away ash, %
+	N     float64 `json:"n"`     // Ash capture efficiency of the gas cleaning
+	Ks    float64 `json:"ks"`    // Emission of solids from sulfur-capture additives, g/GJ
+	Alpha float64 `json:"alpha"` // Correction factor for the ash carry-over
+
+	S      float64 `json:"s"`      // Sulfur content of the working mass, %
+	EtaSO2 float64 `json:"etaSO2"` // Share of sulfur oxides bound by the fly ash
+	C      float64 `json:"c"`      // Carbon content of the working mass, % (gas: kg of carbon per 100 m³)
+	NOx    float64 `json:"nox"`    // NOx emission factor at nominal load in the reference boiler, g/GJ
+	Q3     float64 `json:"q3"`     // Heat loss from incomplete chemical combustion, %
+	Q4     float64 `json:"q4"`     // Heat loss from incomplete mechanical combustion, %
+	R      float64 `json:"r"`      // Share of the q3 loss caused by CO
+
+	// Gas marks a gaseous fuel: its coefficients are per m³ instead of per kg, so Qri is in MJ/m³,
+	// C in kg of carbon per 100 m³ (C/100 kg per m³) and the amount in thousand m³.
+	Gas bool `json:"gas,omitempty"`
+}
+
+// ValidationError reports invalid fuel parameters.
+type ValidationError string
+
+func (e ValidationError) Error() string { return string(e) }
+
+// Validate checks that the coefficients are physically meaningful.
+func (f FuelParams) Validate() error {
+	switch {
+	case !fuelID.MatchString(f.ID):
+		return ValidationError("ідентифікатор має містити лише латинські літери, цифри, '-' або '_'")
+	case strings.TrimSpace(f.Name) == "":
+		return ValidationError("назва палива обов'язкова")
+	case f.Qri <= 0:
+		return ValidationError("Qri має бути більше 0")
+	case f.A < 0 || f.A > 1:
+		return ValidationError("A має бути в межах 0–1")
+	case f.Ar < 0 || f.Ar > 100:
+		return ValidationError("Ar має бути в межах 0–100 %")
+	case f.G < 0 || f.G >= 100:
+		return ValidationError("G має бути в межах 0–100 %")
+	case f.N < 0 || f.N > 1:
+		return ValidationError("N має бути в межах 0–1")
+	case f.Ks < 0:
+		return ValidationError("Ks не може бути від'ємним")
+	case f.Alpha < 0:
+		return ValidationError("alpha не може бути від'ємним")
+	case f.S < 0 || f.S > 100:
+		return ValidationError("Sr має бути в межах 0–100 %")
+	case f.EtaSO2 < 0 || f.EtaSO2 > 1:
+		return ValidationError("ηSO2 має бути в межах 0–1")
+	case f.C < 0 || f.C > 100:
+		return ValidationError("Cr має бути в межах 0–100 %")
+	case f.NOx < 0:
+		return ValidationError("kNOx не може бути від'ємним")
+	case f.Q3 < 0 || f.Q3 > 100:
+		return ValidationError("q3 має бути в межах 0–100 %")
+	case f.Q4 < 0 || f.Q4 >= 100:
+		return ValidationError("q4 має бути в межах 0–100 %")
+	case f.R < 0 || f.R > 1:
+		return ValidationError("R має бути в межах 0–1")
+	}
+	return nil
+}
+
+var fuelID = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+// Coefficient describes one editable coefficient for the forms.
+type Coefficient struct {
+	Key   string // Form field and JSON key
+	Label string
+	Hint  string
+}
+
+// coefficients lists the coefficients in form order.
+var coefficients = []Coefficient{
+	{"qri", "Qri", "нижча робоча теплота згоряння, МДж/кг (для газу — МДж/м³)"},
+	{"a", "A", "частка золи, що виходить з топки (a_вин)"},
+	{"ar", "Ar", "масовий вміст золи в робочій масі, %"},
+	{"g", "G", "вміст горючих у леткій золі, %"},
+	{"n", "N", "ефективність золовловлення (0–1)"},
+	{"ks", "Ks", "викид твердих продуктів взаємодії сорбенту, г/ГДж"},
+	{"alpha", "alpha", "поправковий коефіцієнт виносу золи"},
+	{"s", "Sr", "вміст сірки в робочій масі, %"},
+	{"etaSO2", "ηSO2", "частка оксидів сірки, що зв'язується леткою золою"},
+	{"c", "Cr", "вміст вуглецю в робочій масі, % (для газу — кг вуглецю на 100 м³)"},
+	{"nox", "kNOx", "показник емісії NOx за номінального навантаження, г/ГДж"},
+	{"q3", "q3", "втрати теплоти від хімічної неповноти згоряння, %"},
+	{"q4", "q4", "втрати теплоти від механічної неповноти згоряння, %"},
+	{"r", "R", "частка втрат q3, зумовлена CO"},
+}
+
+// Field returns a pointer to the coefficient with the given key, or nil.
+func (f *FuelParams) Field(key string) *float64 {
+	switch key {
+	case "qri":
+		return &f.Qri
+	case "a":
+		return &f.A
+	case "ar":
+		return &f.Ar
+	case "g":
+		return &f.G
+	case "n":
+		return &f.N
+	case "ks":
+		return &f.Ks
+	case "alpha":
+		return &f.Alpha
+	case "s":
+		return &f.S
+	case "etaSO2":
+		return &f.EtaSO2
+	case "c":
+		return &f.C
+	case "nox":
+		return &f.NOx
+	case "q3":
+		return &f.Q3
+	case "q4":
+		return &f.Q4
+	case "r":
+		return &f.R
+	}
+	return nil
+}
+
+// Value returns the coefficient with the given key (used by the templates).
+func (f FuelParams) Value(key string) float64 {
+	if v := f.Field(key); v != nil {
+		return *v
+	}
+	return 0
+}
+
+// Registry errors.
+var (
+	ErrFuelNotFound    = errors.New("паливо не знайдено")
+	ErrFuelExists      = errors.New("паливо з таким ідентифікатором вже існує")
+	ErrVersionNotFound = errors.New("версію реєстру не знайдено")
+)
+
+// Snapshot is the registry contents at one version.
+type Snapshot struct {
+	Version int          `json:"version"` // Incremented on every change
+	Updated time.Time    `json:"updated"`
+	Fuels   []FuelParams `json:"fuels"`
+}
+
+// Fuel returns the fuel with the given ID in the snapshot.
+func (s Snapshot) Fuel(id string) (FuelParams, error) {
+	for _, f := range s.Fuels {
+		if f.ID == id {
+			return f, nil
+		}
+	}
+	return FuelParams{}, ErrFuelNotFound
+}
+
+// registryFile is the on-disk format of the fuel registry: the current version
+// and every earlier one, so that a result can be traced to the parameters it used.
+type registryFile struct {
+	Snapshot
+	History []Snapshot `json:"history,omitempty"` // Earlier versions, oldest first
+}
+
+// Registry is the fuel parameter store backed by a JSON data file.
+type Registry struct {
+	mu   sync.Mutex
+	path string
+	data registryFile
+}
+
+// OpenRegistry loads the registry from the data file at path. Until the first change
+// is saved there, the registry starts from the seed file.
+func OpenRegistry(path, seed string) (*Registry, error) {
+	file := path
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		file = seed
+		raw, err = os.ReadFile(seed)
+	}
+	if err != nil {
+		return nil, err
+	}
+	reg := &Registry{path: path}
+	if err := json.Unmarshal(raw, &reg.data); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	for _, f := range reg.data.Fuels {
+		if err := f.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: паливо %q: %w", file, f.ID, err)
+		}
+	}
+	return reg, nil
+}
+
+// Version returns the current version of the registry data.
+func (reg *Registry) Version() int {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return reg.data.Version
+}
+
+// List returns all fuels in file order.
+func (reg *Registry) List() []FuelParams {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return append([]FuelParams(nil), reg.data.Fuels...)
+}
+
+// Get returns the fuel with the given ID.
+func (reg *Registry) Get(id string) (FuelParams, error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	return reg.data.Fuel(id)
+}
+
+// Snapshot returns the registry at the given version; 0 is the current one.
+func (reg *Registry) Snapshot(version int) (Snapshot, error) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if version == 0 || version == reg.data.Version {
+		return reg.data.Snapshot, nil
+	}
+	for _, s := range reg.data.History {
+		if s.Version == version {
+			return s, nil
+		}
+	}
+	return Snapshot{}, ErrVersionNotFound
+}
+
+// History returns the earlier versions of the registry, newest first.
+func (reg *Registry) History() []Snapshot {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	history := make([]Snapshot, len(reg.data.History))
+	for i, s := range reg.data.History {
+		history[len(history)-1-i] = s
+	}
+	return history
+}
+
+// Create adds a new fuel.
+func (reg *Registry) Create(f FuelParams) error {
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	if reg.index(f.ID) >= 0 {
+		return ErrFuelExists
+	}
+	return reg.commit(append(reg.fuels(), f))
+}
+
+// Update replaces the fuel with the given ID; the ID itself cannot change.
+func (reg *Registry) Update(id string, f FuelParams) error {
+	f.ID = id
+	if err := f.Validate(); err != nil {
+		return err
+	}
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	i := reg.index(id)
+	if i < 0 {
+		return ErrFuelNotFound
+	}
+	fuels := reg.fuels()
+	fuels[i] = f
+	return reg.commit(fuels)
+}
+
+// Delete removes the fuel with the given ID.
+func (reg *Registry) Delete(id string) error {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	i := reg.index(id)
+	if i < 0 {
+		return ErrFuelNotFound
+	}
+	fuels := reg.fuels()
+	return reg.commit(append(fuels[:i], fuels[i+1:]...))
+}
+
+// index returns the position of the fuel or -1 (call with reg.mu held).
+func (reg *Registry) index(id string) int {
+	for i, f := range reg.data.Fuels {
+		if f.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// fuels returns a copy of the current fuels to build the next version from (call with reg.mu held).
+func (reg *Registry) fuels() []FuelParams {
+	return append([]FuelParams(nil), reg.data.Fuels...)
+}
+
+// commit writes fuels as the next version, keeping the current one in the history, and
+// switches to it only once the data file has been replaced (call with reg.mu held).
+func (reg *Registry) commit(fuels []FuelParams) error {
+	next := registryFile{
+		Snapshot: Snapshot{
+			Version: reg.data.Version + 1,
+			Updated: time.Now().UTC().Truncate(time.Second),
+			Fuels:   fuels,
+		},
+		History: append(reg.data.History[:len(reg.data.History):len(reg.data.History)], reg.data.Snapshot),
+	}
+	raw, err := json.MarshalIndent(next, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := reg.path + ".tmp"
+	if err := os.WriteFile(tmp, append(raw, '\n'), 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, reg.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	reg.data = next
+	return nil
+}
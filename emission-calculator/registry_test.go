@@ -0,0 +1,110 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// openTestRegistry opens a registry in a temporary directory, starting from the tracked seed.
+func openTestRegistry(t *testing.T) *Registry {
+	t.Helper()
+	reg, err := OpenRegistry(filepath.Join(t.TempDir(), "fuels.json"), registrySeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return reg
+}
+
+func TestOpenRegistryFromSeed(t *testing.T) {
+	reg := openTestRegistry(t)
+	if reg.Version() != 2 || len(reg.List()) != 3 {
+		t.Fatalf("seed: version %d, %d fuels; want 2, 3", reg.Version(), len(reg.List()))
+	}
+	// The parameters of version 1 ship with the seed, so earlier results stay reproducible
+	v1, err := reg.Snapshot(1)
+	if err != nil {
+		t.Fatalf("seed lacks version 1: %v", err)
+	}
+	if coal, _ := v1.Fuel("coal"); coal.Qri != 20.47 || coal.C != 0 {
+		t.Errorf("version 1 coal = %+v", coal)
+	}
+	if _, err := os.Stat(reg.path); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("data file written before the first change: %v", err)
+	}
+	if _, err := OpenRegistry(filepath.Join(t.TempDir(), "fuels.json"), "missing.json"); err == nil {
+		t.Error("missing seed accepted")
+	}
+}
+
+func TestRegistryHistory(t *testing.T) {
+	reg := openTestRegistry(t)
+	coal, _ := reg.Get("coal")
+	peat := coal
+	peat.ID, peat.Name, peat.Qri = "peat", "Торф", 8.12
+
+	steps := []struct {
+		name    string
+		change  func() error
+		version int
+		fuels   int
+	}{
+		{"create", func() error { return reg.Create(peat) }, 3, 4},
+		{"update", func() error { coal.N = 0.99; return reg.Update("coal", coal) }, 4, 4},
+		{"delete", func() error { return reg.Delete("gas") }, 5, 3},
+	}
+	for _, s := range steps {
+		if err := s.change(); err != nil {
+			t.Fatalf("%s: %v", s.name, err)
+		}
+		if reg.Version() != s.version || len(reg.List()) != s.fuels {
+			t.Errorf("%s: version %d, %d fuels; want %d, %d", s.name, reg.Version(), len(reg.List()), s.version, s.fuels)
+		}
+	}
+
+	// Every earlier version stays available, newest first
+	history := reg.History()
+	if len(history) != 4 || history[0].Version != 4 || history[2].Version != 2 || history[3].Version != 1 {
+		t.Fatalf("history versions %+v", history)
+	}
+	v3, err := reg.Snapshot(3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f, _ := v3.Fuel("coal"); f.N != 0.985 {
+		t.Errorf("version 3 coal N = %g, want 0.985", f.N)
+	}
+	if _, err := v3.Fuel("gas"); err != nil {
+		t.Errorf("version 3 lost gas: %v", err)
+	}
+	if _, err := reg.Snapshot(99); !errors.Is(err, ErrVersionNotFound) {
+		t.Errorf("unknown version: %v", err)
+	}
+
+	// The history survives a restart
+	reopened, err := OpenRegistry(reg.path, registrySeed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if reopened.Version() != 5 || len(reopened.History()) != 4 {
+		t.Errorf("reopened: version %d with %d earlier versions", reopened.Version(), len(reopened.History()))
+	}
+}
+
+func TestRegistryUnchangedOnWriteError(t *testing.T) {
+	reg := openTestRegistry(t)
+	reg.path = filepath.Join(t.TempDir(), "missing", "fuels.json")
+	coal, _ := reg.Get("coal")
+
+	coal.N = 0.5
+	if err := reg.Update("coal", coal); err == nil {
+		t.Fatal("update saved to a missing directory")
+	}
+	if err := reg.Delete("gas"); err == nil {
+		t.Fatal("delete saved to a missing directory")
+	}
+	if f, _ := reg.Get("coal"); f.N != 0.985 || len(reg.List()) != 3 || reg.Version() != 2 || len(reg.History()) != 1 {
+		t.Errorf("registry changed after failed saves: N %g, %d fuels, version %d", f.N, len(reg.List()), reg.Version())
+	}
+}
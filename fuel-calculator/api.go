@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/aletiaa/fuel-calculator/fuel"
+)
+
+// writeJSON - відправляє значення v у форматі JSON
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeJSONError - відповідає 400 з переліком помилок перевірки у форматі JSON
+func writeJSONError(w http.ResponseWriter, err error) {
+	var errs fuel.ValidationErrors
+	if !errors.As(err, &errs) {
+		errs = fuel.ValidationErrors{{Message: err.Error()}}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(map[string]any{"errors": errs})
+}
+
+// decodeJSON - зчитує тіло POST-запиту в v; у разі помилки відповідає клієнту і повертає false
+func decodeJSON(w http.ResponseWriter, r *http.Request, v any) bool {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	return decodeBody(w, r, v)
+}
+
+// decodeBody - зчитує тіло запиту в v без перевірки методу
+func decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	if err := json.NewDecoder(r.Body).Decode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return false
+	}
+	return true
+}
+
+// apiTask1 - JSON-версія "Завдання 1": приймає fuel.Composition робочої маси,
+// формулу теплоти згоряння можна обрати параметром ?correlation=
+func apiTask1(w http.ResponseWriter, r *http.Request) {
+	var in fuel.Composition
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if err := in.Validate(); err != nil {
+		writeJSONError(w, err)
+		return
+	}
+	corr, err := fuel.ParseCorrelation(r.URL.Query().Get("correlation"))
+	if err != nil {
+		writeJSONError(w, fuel.ValidationErrors{{Field: "correlation", Message: err.Error()}})
+		return
+	}
+	res, _ := fuel.AnalyzeWith(in, corr)
+	writeJSON(w, res)
+}
+
+// apiTask2 - JSON-версія "Завдання 2": приймає fuel.MazutInput
+func apiTask2(w http.ResponseWriter, r *http.Request) {
+	var in fuel.MazutInput
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if err := in.Validate(); err != nil {
+		writeJSONError(w, err)
+		return
+	}
+	writeJSON(w, fuel.AnalyzeMazut(in))
+}
+
+// convertRequest - тіло запиту для /api/convert
+type convertRequest struct {
+	fuel.BasisInput
+	From        string           `json:"from"`
+	To          string           `json:"to"`
+	Composition fuel.Composition `json:"composition"`
+}
+
+// apiConvert - JSON-версія перерахунку складу між масами
+func apiConvert(w http.ResponseWriter, r *http.Request) {
+	var in convertRequest
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if err := in.Composition.ValidateComponents(); err != nil {
+		writeJSONError(w, err)
+		return
+	}
+	res, err := convertComposition(in.BasisInput, in.From, in.To, in.Composition)
+	if err != nil {
+		writeJSONError(w, err)
+		return
+	}
+	writeJSON(w, res)
+}
+
+// combustionRequest - тіло запиту для /api/combustion
+type combustionRequest struct {
+	Composition fuel.Composition `json:"composition"` // Робоча маса
+	Alpha       float64          `json:"alpha"`       // 0 - fuel.DefaultExcessAir
+}
+
+// apiCombustion - повітря та продукти згоряння для робочої маси
+func apiCombustion(w http.ResponseWriter, r *http.Request) {
+	var in combustionRequest
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if err := in.Composition.Validate(); err != nil {
+		writeJSONError(w, err)
+		return
+	}
+	if in.Alpha == 0 {
+		in.Alpha = fuel.DefaultExcessAir
+	}
+	res, err := fuel.Combust(in.Composition, in.Alpha)
+	if err != nil {
+		writeJSONError(w, fuel.ValidationErrors{{Field: "alpha", Message: err.Error()}})
+		return
+	}
+	writeJSON(w, res)
+}
+
+// blendRequest - тіло запиту для /api/blend
+type blendRequest struct {
+	Components []struct {
+		Fuel        string            `json:"fuel"`        // ID палива з бібліотеки
+		Composition *fuel.Composition `json:"composition"` // або склад робочої маси
+		Fraction    float64           `json:"fraction"`
+	} `json:"components"`
+	Basis       fuel.FractionBasis `json:"basis"`       // "mass" (за замовчуванням) або "heat"
+	Correlation string             `json:"correlation"` // Формула теплоти згоряння
+	Target      *struct {
+		Property fuel.BlendTarget `json:"property"` // "lhv" або "sulfur"
+		Value    float64          `json:"value"`
+	} `json:"target"`
+}
+
+// apiBlend - JSON-версія суміші палив
+func apiBlend(w http.ResponseWriter, r *http.Request) {
+	var in blendRequest
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	corr, err := fuel.ParseCorrelation(in.Correlation)
+	if err != nil {
+		writeJSONError(w, fuel.ValidationErrors{{Field: "correlation", Message: err.Error()}})
+		return
+	}
+
+	components := make([]fuel.BlendComponent, len(in.Components))
+	for i, c := range in.Components {
+		switch {
+		case c.Fuel != "":
+			e, err := library.Get(c.Fuel)
+			if err != nil {
+				writeLibraryError(w, err)
+				return
+			}
+			components[i] = fuel.BlendComponent{Name: e.Name, Composition: e.WorkingComposition()}
+		case c.Composition != nil:
+			if err := c.Composition.Validate(); err != nil {
+				writeJSONError(w, err)
+				return
+			}
+			components[i] = fuel.BlendComponent{Name: fmt.Sprintf("#%d", i+1), Composition: *c.Composition}
+		default:
+			writeJSONError(w, fmt.Errorf("компонент %d: потрібно вказати fuel або composition", i+1))
+			return
+		}
+		components[i].Fraction = c.Fraction
+	}
+
+	if in.Basis == "" {
+		in.Basis = fuel.MassFraction
+	}
+	var target fuel.BlendTarget
+	value := 0.0
+	if in.Target != nil {
+		if in.Target.Property == "" {
+			writeJSONError(w, fuel.ValidationErrors{{Field: "target.property", Message: "вкажіть цільову властивість (lhv або sulfur)"}})
+			return
+		}
+		target, value = in.Target.Property, in.Target.Value
+	}
+	res, err := blendFuels(components, in.Basis, corr, target, value)
+	if err != nil {
+		writeJSONError(w, err)
+		return
+	}
+	writeJSON(w, res)
+}
+
+// consumptionRequest - тіло запиту для /api/consumption: задається lhv або склад робочої маси
+type consumptionRequest struct {
+	fuel.ConsumptionInput
+	Composition *fuel.Composition `json:"composition"`
+	Correlation string            `json:"correlation"`
+}
+
+// apiConsumption - витрата палива котлом; якщо lhv не задано, вона рахується за складом
+func apiConsumption(w http.ResponseWriter, r *http.Request) {
+	var in consumptionRequest
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	if in.Composition != nil {
+		if err := in.Composition.Validate(); err != nil {
+			writeJSONError(w, err)
+			return
+		}
+		corr, err := fuel.ParseCorrelation(in.Correlation)
+		if err != nil {
+			writeJSONError(w, fuel.ValidationErrors{{Field: "correlation", Message: err.Error()}})
+			return
+		}
+		hv, _ := fuel.HeatingValuesFor(*in.Composition, corr)
+		in.LHV = hv.Working.Lower
+	}
+	res, err := fuel.FuelConsumption(in.ConsumptionInput)
+	if err != nil {
+		writeJSONError(w, err)
+		return
+	}
+	writeJSON(w, res)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/aletiaa/fuel-calculator/fuel"
+)
+
+// Кількість рядків палива у формі суміші
+const blendRows = 3
+
+// --------------------- Суміш палив ---------------------
+// calculateBlend - обробник форми суміші палив з бібліотеки
+func calculateBlend(w http.ResponseWriter, r *http.Request) {
+	f := newForm(r)
+
+	var components []fuel.BlendComponent
+	for i := 1; i <= blendRows; i++ {
+		n := strconv.Itoa(i)
+		id := f.text("bfuel" + n)
+		if id == "" {
+			continue
+		}
+		e, err := library.Get(id)
+		if err != nil {
+			f.fail("bfuel"+n, err.Error())
+			continue
+		}
+		components = append(components, fuel.BlendComponent{
+			Name:        e.Name,
+			Composition: e.WorkingComposition(),
+			Fraction:    f.optionalFloat("bfrac" + n),
+		})
+	}
+	if len(components) == 0 {
+		f.fail("bfuel1", "оберіть хоча б одне паливо")
+	}
+	basis := fuel.FractionBasis(f.text("bbasis"))
+	if basis == "" {
+		basis = fuel.MassFraction
+	}
+	corr, err := fuel.ParseCorrelation(f.text("bcorr"))
+	if err != nil {
+		f.fail("bcorr", err.Error())
+	}
+	target, err := fuel.ParseBlendTarget(f.text("btarget"))
+	if err != nil {
+		f.fail("btarget", err.Error())
+	}
+	value := 0.0
+	if target != "" {
+		value = f.float("bvalue")
+	}
+
+	result := ""
+	if f.valid() {
+		res, err := blendFuels(components, basis, corr, target, value)
+		if err != nil {
+			f.fail("", err.Error())
+		} else {
+			result = formatBlend(res)
+		}
+	}
+
+	tmpl.Execute(w, f.page(result))
+}
+
+// blendResult - результат суміші; Solved заповнюється, якщо співвідношення підбиралось під ціль
+type blendResult struct {
+	fuel.BlendResult
+	Components []fuel.BlendComponent `json:"components"`
+	Solved     *blendSolution        `json:"solved,omitempty"`
+}
+
+// blendSolution - знайдене співвідношення двох палив для цільової властивості
+type blendSolution struct {
+	Target   fuel.BlendTarget `json:"target"`
+	Value    float64          `json:"value"`
+	Fraction float64          `json:"fraction"` // Масова частка першого палива
+}
+
+// blendFuels - змішує палива; якщо задано target, спочатку підбирає масову частку
+// першого палива у суміші з другим
+func blendFuels(components []fuel.BlendComponent, basis fuel.FractionBasis, corr fuel.Correlation, target fuel.BlendTarget, value float64) (blendResult, error) {
+	if _, err := fuel.ParseBlendTarget(string(target)); err != nil {
+		return blendResult{}, err
+	}
+	var solved *blendSolution
+	if target != "" {
+		if len(components) != 2 {
+			return blendResult{}, fmt.Errorf("підбір співвідношення можливий лише для двох палив")
+		}
+		x, err := fuel.SolveBlendRatio(components[0].Composition, components[1].Composition, target, value, corr)
+		if err != nil {
+			return blendResult{}, err
+		}
+		components[0].Fraction, components[1].Fraction = x, 1-x
+		basis = fuel.MassFraction
+		solved = &blendSolution{Target: target, Value: value, Fraction: x}
+	}
+
+	res, err := fuel.Blend(components, basis, corr)
+	if err != nil {
+		return blendResult{}, err
+	}
+	return blendResult{BlendResult: res, Components: components, Solved: solved}, nil
+}
+
+// formatBlend - формує текстовий результат суміші
+func formatBlend(res blendResult) string {
+	var b strings.Builder
+
+	if s := res.Solved; s != nil {
+		name := map[fuel.BlendTarget]string{
+			fuel.TargetLHV:    "нижча теплота %.3f МДж/кг",
+			fuel.TargetSulfur: "сірка %.3f %%",
+		}[s.Target]
+		fmt.Fprintf(&b, "\nЦіль: "+name+"\n", s.Value)
+		fmt.Fprintf(&b, "Частка «%s» за масою: %.2f %%\n", res.Components[0].Name, s.Fraction*100)
+	}
+
+	b.WriteString("\nСклад суміші (частки за масою / за теплом):\n")
+	for i, c := range res.Components {
+		fmt.Fprintf(&b, "  %s: %.2f %% / %.2f %%\n", c.Name, res.MassFractions[i]*100, res.HeatFractions[i]*100)
+	}
+
+	p := res.Analysis.Working
+	fmt.Fprintf(&b, `
+Робоча маса суміші:
+  Hp = %.3f %%
+  Cp = %.3f %%
+  Sp = %.3f %%
+  Np = %.3f %%
+  Op = %.3f %%
+  Wp = %.3f %%
+  Ap = %.3f %%
+`, p.H, p.C, p.S, p.N, p.O, p.W, p.A)
+
+	return b.String() + formatTask1(res.Analysis)
+}
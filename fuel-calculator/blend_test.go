@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aletiaa/fuel-calculator/fuel"
+)
+
+func TestBlendFuelsTargetErrors(t *testing.T) {
+	c := fuel.Composition{H: 3.8, C: 55.2, S: 3.2, N: 1.0, O: 5.8, W: 10, A: 21.0}
+	three := []fuel.BlendComponent{{Composition: c, Fraction: 1}, {Composition: c, Fraction: 1}, {Composition: c, Fraction: 1}}
+
+	for _, tc := range []struct {
+		name       string
+		components []fuel.BlendComponent
+		target     fuel.BlendTarget
+		err        string
+	}{
+		{"unknown target", three, "ash", "невідома цільова властивість"},
+		{"three fuels", three, fuel.TargetSulfur, "лише для двох палив"},
+	} {
+		_, err := blendFuels(tc.components, fuel.MassFraction, fuel.Mendeleev, tc.target, 1)
+		if err == nil || !strings.Contains(err.Error(), tc.err) {
+			t.Errorf("%s: error %v, want %q", tc.name, err, tc.err)
+		}
+	}
+}
@@ -0,0 +1,133 @@
+package main
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/aletiaa/fuel-calculator/fuel"
+)
+
+// pageData - дані для шаблону index.html
+type pageData struct {
+	Result string
+	Values map[string]string // Введені користувачем значення (щоб не губити їх після помилки)
+	Errors map[string]string // Помилки по полях форми: ім'я поля -> повідомлення
+	Fuels  []fuel.Entry      // Бібліотека палив для вибору
+}
+
+// form - зчитує поля HTML-форми та накопичує помилки по кожному полю
+type form struct {
+	r        *http.Request
+	values   map[string]string
+	errors   map[string]string
+	messages []string
+}
+
+func newForm(r *http.Request) *form {
+	return &form{
+		r:      r,
+		values: map[string]string{},
+		errors: map[string]string{},
+	}
+}
+
+// fail - запам'ятовує помилку для поля name (порожнє name - помилка всієї форми)
+func (f *form) fail(name, msg string) {
+	if name != "" {
+		if _, ok := f.errors[name]; ok {
+			return
+		}
+		f.errors[name] = msg
+		msg = name + ": " + msg
+	}
+	f.messages = append(f.messages, msg)
+}
+
+// text - зчитує рядкове поле без перевірки
+func (f *form) text(name string) string {
+	v := strings.TrimSpace(f.r.FormValue(name))
+	f.values[name] = v
+	return v
+}
+
+// float - зчитує обов'язкове числове поле
+func (f *form) float(name string) float64 {
+	v := f.text(name)
+	if v == "" {
+		f.fail(name, "поле обов'язкове")
+		return 0
+	}
+	return f.parse(name, v)
+}
+
+// optionalFloat - зчитує необов'язкове числове поле (порожнє значення - 0)
+func (f *form) optionalFloat(name string) float64 {
+	v := f.text(name)
+	if v == "" {
+		return 0
+	}
+	return f.parse(name, v)
+}
+
+func (f *form) parse(name, v string) float64 {
+	x, err := checkAndToDouble(v)
+	if err != nil || math.IsNaN(x) || math.IsInf(x, 0) {
+		f.fail(name, "некоректне число")
+		return 0
+	}
+	return x
+}
+
+// check - переносить помилки перевірки з пакета fuel на поля форми.
+// fieldName перетворює назву поля з пакета fuel на ім'я поля HTML-форми.
+func (f *form) check(err error, fieldName func(string) string) {
+	if err == nil {
+		return
+	}
+	var errs fuel.ValidationErrors
+	if !errors.As(err, &errs) {
+		f.fail("", err.Error())
+		return
+	}
+	for _, fe := range errs {
+		name := fe.Field
+		if name != "" {
+			name = fieldName(name)
+		}
+		f.fail(name, fe.Message)
+	}
+}
+
+// valid - чи немає помилок у формі
+func (f *form) valid() bool {
+	return len(f.messages) == 0
+}
+
+// page - формує дані для шаблону; при помилках результатом є їх перелік
+func (f *form) page(result string) pageData {
+	if !f.valid() {
+		result = "\nПомилки у введених даних:\n  " + strings.Join(f.messages, "\n  ") + "\n"
+	}
+	return pageData{Result: result, Values: f.values, Errors: f.errors, Fuels: library.List()}
+}
+
+// sameName - поля форми збігаються з назвами полів у пакеті fuel
+func sameName(field string) string {
+	return field
+}
+
+// withSuffix - поля форми мають суфікс маси (наприклад, "h" -> "hp")
+func withSuffix(suffix string) func(string) string {
+	return func(field string) string {
+		return field + suffix
+	}
+}
+
+// withPrefix - поля форми мають префікс (наприклад, "w" -> "xw")
+func withPrefix(prefix string) func(string) string {
+	return func(field string) string {
+		return prefix + field
+	}
+}
@@ -0,0 +1,117 @@
+package fuel
+
+import "fmt"
+
+// Basis - маса, на яку віднесено склад палива
+type Basis string
+
+const (
+	AsReceived Basis = "ar"  // Робоча маса
+	AirDried   Basis = "ad"  // Аналітична (повітряно-суха) маса
+	Dry        Basis = "d"   // Суха маса
+	DryAshFree Basis = "daf" // Горюча маса
+	Organic    Basis = "o"   // Органічна маса (без колчеданної сірки)
+)
+
+// Bases - усі маси у порядку від робочої до органічної
+var Bases = []Basis{AsReceived, AirDried, Dry, DryAshFree, Organic}
+
+// basisNames - українські назви мас для виводу
+var basisNames = map[Basis]string{
+	AsReceived: "робоча",
+	AirDried:   "аналітична",
+	Dry:        "суха",
+	DryAshFree: "горюча",
+	Organic:    "органічна",
+}
+
+// Name - українська назва маси
+func (b Basis) Name() string {
+	if n, ok := basisNames[b]; ok {
+		return n
+	}
+	return string(b)
+}
+
+// ParseBasis - перевіряє, що рядок є відомою масою
+func ParseBasis(s string) (Basis, error) {
+	b := Basis(s)
+	if _, ok := basisNames[b]; !ok {
+		return "", fmt.Errorf("невідома маса %q", s)
+	}
+	return b, nil
+}
+
+// BasisInput - волога та баласт палива, за якими будується таблиця перерахунку
+type BasisInput struct {
+	Wr  float64 `json:"wr"`  // Волога робочої маси (%)
+	Wad float64 `json:"wad"` // Волога аналітичної маси (%)
+	Ad  float64 `json:"ad"`  // Зола на суху масу (%)
+	Skd float64 `json:"skd"` // Колчеданна сірка на суху масу (%)
+}
+
+// Ballast - волога, зола та колчеданна сірка на одній масі (%)
+type Ballast struct {
+	W  float64 `json:"w"`
+	A  float64 `json:"a"`
+	Sk float64 `json:"sk"`
+}
+
+// organic - частка органічної маси на цій масі (%)
+func (b Ballast) organic() float64 {
+	return 100 - b.W - b.A - b.Sk
+}
+
+// ConversionTable - волога/зола на кожній масі та коефіцієнти перерахунку між ними
+type ConversionTable struct {
+	Ballast map[Basis]Ballast `json:"ballast"`
+	// Factors[from][to] - множник для переходу складу з маси from на масу to
+	Factors map[Basis]map[Basis]float64 `json:"factors"`
+}
+
+// NewConversionTable - будує таблицю перерахунку для всіх пар мас.
+// Інваріантом є органічна маса: x_to = x_from * o_to / o_from,
+// де o - частка органічної речовини на відповідній масі.
+func NewConversionTable(in BasisInput) (ConversionTable, error) {
+	if err := in.Validate(); err != nil {
+		return ConversionTable{}, err
+	}
+
+	// Перехід із сухої маси на масу з вологою W: множник (100 - W) / 100
+	fromDry := func(w float64) Ballast {
+		k := (100 - w) / 100
+		return Ballast{W: w, A: in.Ad * k, Sk: in.Skd * k}
+	}
+	kdaf := 100 / (100 - in.Ad)
+
+	ballast := map[Basis]Ballast{
+		AsReceived: fromDry(in.Wr),
+		AirDried:   fromDry(in.Wad),
+		Dry:        fromDry(0),
+		DryAshFree: {Sk: in.Skd * kdaf},
+		Organic:    {},
+	}
+
+	factors := make(map[Basis]map[Basis]float64, len(Bases))
+	for _, from := range Bases {
+		factors[from] = make(map[Basis]float64, len(Bases))
+		for _, to := range Bases {
+			factors[from][to] = ballast[to].organic() / ballast[from].organic()
+		}
+	}
+	return ConversionTable{Ballast: ballast, Factors: factors}, nil
+}
+
+// Convert - перераховує склад c з маси from на масу to.
+// Колчеданна сірка не входить до органічної маси, тож сірка перераховується окремо.
+// Волога та зола результату беруться з таблиці для маси to.
+func (t ConversionTable) Convert(c Composition, from, to Basis) Composition {
+	k := t.Factors[from][to]
+	src, dst := t.Ballast[from], t.Ballast[to]
+
+	res := c.Scale(k)
+	res.S = (c.S-src.Sk)*k + dst.Sk
+	res.W = dst.W
+	res.A = dst.A
+	return res
+}
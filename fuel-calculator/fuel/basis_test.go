@@ -0,0 +1,80 @@
+package fuel
+
+import (
+	"math"
+	"testing"
+)
+
+var basisInput = BasisInput{Wr: 10, Wad: 2, Ad: 20, Skd: 1.5}
+
+func TestConversionFactors(t *testing.T) {
+	table, err := NewConversionTable(basisInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		from, to Basis
+		want     float64
+	}{
+		{AsReceived, AsReceived, 1},
+		{AsReceived, AirDried, 1.088889},
+		{AsReceived, Dry, 1.111111},
+		{AsReceived, DryAshFree, 1.388889},
+		{AsReceived, Organic, 1.415428},
+		{Dry, DryAshFree, 1.25},
+		{DryAshFree, AsReceived, 0.72},
+		{Organic, AirDried, 0.7693},
+	} {
+		if got := table.Factors[c.from][c.to]; math.Abs(got-c.want) > 1e-6 {
+			t.Errorf("%s -> %s = %v, want %v", c.from, c.to, got, c.want)
+		}
+	}
+}
+
+func TestConvert(t *testing.T) {
+	table, err := NewConversionTable(basisInput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	working := Composition{H: 3.8, C: 55.2, S: 3.2, N: 1.0, O: 5.8, W: 10, A: 18}
+	for _, c := range []struct {
+		to             Basis
+		h, cc, s, w, a float64
+	}{
+		{AsReceived, 3.8, 55.2, 3.2, 10, 18},
+		{Dry, 4.222222, 61.333333, 3.555556, 0, 20},
+		{DryAshFree, 5.277778, 76.666667, 4.444444, 0, 0},
+		// Pyritic sulphur is not part of the organic mass
+		{Organic, 5.378627, 78.131635, 2.618542, 0, 0},
+	} {
+		got := table.Convert(working, AsReceived, c.to)
+		for _, v := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"H", got.H, c.h}, {"C", got.C, c.cc}, {"S", got.S, c.s}, {"W", got.W, c.w}, {"A", got.A, c.a},
+		} {
+			if math.Abs(v.got-v.want) > 1e-6 {
+				t.Errorf("%s %s = %v, want %v", c.to, v.name, v.got, v.want)
+			}
+		}
+
+		// Converting back restores the working mass
+		back := table.Convert(got, c.to, AsReceived)
+		if math.Abs(back.C-working.C) > 1e-9 || math.Abs(back.S-working.S) > 1e-9 {
+			t.Errorf("%s -> ar = %+v, want %+v", c.to, back, working)
+		}
+	}
+}
+
+func TestConversionTableValidation(t *testing.T) {
+	for _, in := range []BasisInput{
+		{Wr: 100, Ad: 20},
+		{Wr: 10, Wad: -1, Ad: 20},
+		{Wr: 10, Ad: 90, Skd: 15},
+	} {
+		if _, err := NewConversionTable(in); err == nil {
+			t.Errorf("%+v accepted", in)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package fuel
+
+import (
+	"fmt"
+	"math"
+)
+
+// FractionBasis - у чому задано частки компонентів суміші
+type FractionBasis string
+
+const (
+	MassFraction FractionBasis = "mass" // Частка за масою
+	HeatFraction FractionBasis = "heat" // Частка за теплом (нижча теплота робочої маси)
+)
+
+// BlendComponent - одне паливо в суміші
+type BlendComponent struct {
+	Name        string      `json:"name"`
+	Composition Composition `json:"composition"` // Робоча маса
+	Fraction    float64     `json:"fraction"`    // Частка (нормується до суми 1)
+}
+
+// BlendResult - склад та теплота суміші
+type BlendResult struct {
+	Basis         FractionBasis `json:"basis"`
+	MassFractions []float64     `json:"massFractions"` // Частки компонентів за масою
+	HeatFractions []float64     `json:"heatFractions"` // Частки компонентів за теплом
+	Analysis      Analysis      `json:"analysis"`      // Робоча, суха, горюча маси та теплота суміші
+}
+
+// Blend - змішує палива за масовими або тепловими частками; теплота рахується за формулою corr
+func Blend(components []BlendComponent, basis FractionBasis, corr Correlation) (BlendResult, error) {
+	if len(components) == 0 {
+		return BlendResult{}, fmt.Errorf("суміш має містити хоча б одне паливо")
+	}
+	lhv := make([]float64, len(components))
+	for i, c := range components {
+		if c.Fraction < 0 {
+			return BlendResult{}, fmt.Errorf("%s: частка не може бути від'ємною", c.Name)
+		}
+		hv, err := HeatingValuesFor(c.Composition, corr)
+		if err != nil {
+			return BlendResult{}, err
+		}
+		lhv[i] = hv.Working.Lower
+	}
+
+	// Тепло кожного компонента пропорційне масі, помноженій на нижчу теплоту
+	mass := make([]float64, len(components))
+	for i, c := range components {
+		switch basis {
+		case MassFraction:
+			mass[i] = c.Fraction
+		case HeatFraction:
+			if lhv[i] <= 0 {
+				return BlendResult{}, fmt.Errorf("%s: нижча теплота має бути додатною для теплових часток", c.Name)
+			}
+			mass[i] = c.Fraction / lhv[i]
+		default:
+			return BlendResult{}, fmt.Errorf("невідомий тип часток %q", basis)
+		}
+	}
+	mass, err := normalize(mass)
+	if err != nil {
+		return BlendResult{}, err
+	}
+
+	heat := make([]float64, len(components))
+	var working Composition
+	for i, c := range components {
+		working = working.add(c.Composition.Scale(mass[i]))
+		heat[i] = mass[i] * lhv[i]
+	}
+	heat, err = normalize(heat)
+	if err != nil {
+		// Нульова теплота суміші: теплові частки не визначені
+		heat = make([]float64, len(components))
+	}
+
+	analysis, err := AnalyzeWith(working, corr)
+	if err != nil {
+		return BlendResult{}, err
+	}
+	return BlendResult{
+		Basis:         basis,
+		MassFractions: mass,
+		HeatFractions: heat,
+		Analysis:      analysis,
+	}, nil
+}
+
+// BlendTarget - властивість суміші, для якої підбирається співвідношення
+type BlendTarget string
+
+const (
+	TargetLHV    BlendTarget = "lhv"    // Нижча теплота робочої маси, МДж/кг
+	TargetSulfur BlendTarget = "sulfur" // Сірка на робочу масу, %
+)
+
+// ParseBlendTarget - перевіряє назву цільової властивості; порожній рядок означає, що ціль не задано
+func ParseBlendTarget(s string) (BlendTarget, error) {
+	switch t := BlendTarget(s); t {
+	case "", TargetLHV, TargetSulfur:
+		return t, nil
+	}
+	return "", fmt.Errorf("невідома цільова властивість %q (очікується %q або %q)", s, TargetLHV, TargetSulfur)
+}
+
+// SolveBlendRatio - масова частка палива a у суміші з b, за якої властивість target дорівнює value.
+// Обидві властивості лінійні за масовими частками, тож розв'язок знаходиться напряму.
+func SolveBlendRatio(a, b Composition, target BlendTarget, value float64, corr Correlation) (float64, error) {
+	property := func(c Composition) (float64, error) {
+		switch target {
+		case TargetLHV:
+			hv, err := HeatingValuesFor(c, corr)
+			return hv.Working.Lower, err
+		case TargetSulfur:
+			return c.S, nil
+		}
+		return 0, fmt.Errorf("невідома цільова властивість %q", target)
+	}
+	pa, err := property(a)
+	if err != nil {
+		return 0, err
+	}
+	pb, err := property(b)
+	if err != nil {
+		return 0, err
+	}
+	if math.Abs(pa-pb) < 1e-12 {
+		return 0, fmt.Errorf("палива мають однакове значення властивості, співвідношення не визначене")
+	}
+	x := (value - pb) / (pa - pb)
+	if x < 0 || x > 1 {
+		return 0, fmt.Errorf("ціль %.3f недосяжна: для цих палив значення лежить між %.3f та %.3f",
+			value, math.Min(pa, pb), math.Max(pa, pb))
+	}
+	return x, nil
+}
+
+// add - покомпонентна сума двох складів
+func (c Composition) add(o Composition) Composition {
+	return Composition{
+		H: c.H + o.H,
+		C: c.C + o.C,
+		S: c.S + o.S,
+		N: c.N + o.N,
+		O: c.O + o.O,
+		W: c.W + o.W,
+		A: c.A + o.A,
+	}
+}
+
+// normalize - ділить значення на їх суму
+func normalize(v []float64) ([]float64, error) {
+	total := 0.0
+	for _, x := range v {
+		total += x
+	}
+	if total <= 0 {
+		return nil, fmt.Errorf("сума часток має бути додатною")
+	}
+	res := make([]float64, len(v))
+	for i, x := range v {
+		res[i] = x / total
+	}
+	return res, nil
+}
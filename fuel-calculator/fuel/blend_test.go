@@ -0,0 +1,117 @@
+package fuel
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+var (
+	gasCoal    = Composition{H: 3.8, C: 55.2, S: 3.2, N: 1.0, O: 5.8, W: 10, A: 21.0}
+	anthracite = Composition{H: 1.2, C: 63.8, S: 1.7, N: 0.6, O: 1.3, W: 8.5, A: 22.9}
+)
+
+// lhv - нижча теплота робочої маси за Менделєєвим
+func lhv(c Composition) float64 {
+	hv, _ := HeatingValuesFor(c, Mendeleev)
+	return hv.Working.Lower
+}
+
+func TestBlendMassFractions(t *testing.T) {
+	res, err := Blend([]BlendComponent{
+		{Name: "Г", Composition: gasCoal, Fraction: 30},
+		{Name: "АШ", Composition: anthracite, Fraction: 70},
+	}, MassFraction, Mendeleev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := gasCoal.Scale(0.3).add(anthracite.Scale(0.7))
+	got := res.Analysis.Working
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"H", got.H, want.H}, {"C", got.C, want.C}, {"S", got.S, want.S},
+		{"W", got.W, want.W}, {"A", got.A, want.A},
+		{"mass[0]", res.MassFractions[0], 0.3},
+	} {
+		if math.Abs(c.got-c.want) > 1e-9 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	// Heat fractions follow the LHV of the components
+	lhvA, lhvB := lhv(gasCoal), lhv(anthracite)
+	wantHeat := 0.3 * lhvA / (0.3*lhvA + 0.7*lhvB)
+	if math.Abs(res.HeatFractions[0]-wantHeat) > 1e-9 {
+		t.Errorf("heat[0] = %v, want %v", res.HeatFractions[0], wantHeat)
+	}
+}
+
+func TestBlendHeatFractions(t *testing.T) {
+	res, err := Blend([]BlendComponent{
+		{Name: "Г", Composition: gasCoal, Fraction: 0.5},
+		{Name: "АШ", Composition: anthracite, Fraction: 0.5},
+	}, HeatFraction, Mendeleev)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if math.Abs(res.HeatFractions[0]-0.5) > 1e-9 {
+		t.Errorf("heat[0] = %v, want 0.5", res.HeatFractions[0])
+	}
+	lhvA, lhvB := lhv(gasCoal), lhv(anthracite)
+	wantMass := (1 / lhvA) / (1/lhvA + 1/lhvB)
+	if math.Abs(res.MassFractions[0]-wantMass) > 1e-9 {
+		t.Errorf("mass[0] = %v, want %v", res.MassFractions[0], wantMass)
+	}
+}
+
+func TestSolveBlendRatio(t *testing.T) {
+	// Sulfur is linear in the mass fractions: 0.2·3.2 + 0.8·1.7 = 2.0
+	x, err := SolveBlendRatio(gasCoal, anthracite, TargetSulfur, 2.0, Mendeleev)
+	if err != nil || math.Abs(x-0.2) > 1e-12 {
+		t.Errorf("sulfur: x = %v, %v; want 0.2", x, err)
+	}
+
+	// The blend at the solved ratio must have the requested LHV, for every correlation
+	for _, corr := range Correlations {
+		a, _ := HeatingValuesFor(gasCoal, corr)
+		b, _ := HeatingValuesFor(anthracite, corr)
+		target := (a.Working.Lower + b.Working.Lower) / 2
+		x, err := SolveBlendRatio(gasCoal, anthracite, TargetLHV, target, corr)
+		if err != nil {
+			t.Fatalf("%s: %v", corr, err)
+		}
+		res, err := Blend([]BlendComponent{{Composition: gasCoal, Fraction: x}, {Composition: anthracite, Fraction: 1 - x}}, MassFraction, corr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := res.Analysis.HeatingValue.Working.Lower; math.Abs(got-target) > 1e-9 {
+			t.Errorf("%s: blend LHV = %v, want %v", corr, got, target)
+		}
+	}
+
+	for _, c := range []struct {
+		target BlendTarget
+		value  float64
+		err    string
+	}{
+		{TargetSulfur, 5, "недосяжна"},
+		{"ash", 1, "невідома цільова властивість"},
+	} {
+		if _, err := SolveBlendRatio(gasCoal, anthracite, c.target, c.value, Mendeleev); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s=%v: error %v, want %q", c.target, c.value, err, c.err)
+		}
+	}
+}
+
+func TestParseBlendTarget(t *testing.T) {
+	for _, s := range []string{"", "lhv", "sulfur"} {
+		if _, err := ParseBlendTarget(s); err != nil {
+			t.Errorf("%q: %v", s, err)
+		}
+	}
+	if _, err := ParseBlendTarget("ash"); err == nil {
+		t.Error("ash: expected an error")
+	}
+}
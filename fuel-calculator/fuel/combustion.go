@@ -0,0 +1,88 @@
+package fuel
+
+import "fmt"
+
+// DefaultExcessAir - коефіцієнт надлишку повітря, якщо його не задано
+const DefaultExcessAir = 1.2
+
+// AirDensity - густина повітря за нормальних умов (кг/м³)
+const AirDensity = 1.293
+
+// FlueGas - об'єми продуктів згоряння на 1 кг палива (м³/кг, нормальні умови)
+type FlueGas struct {
+	CO2   float64 `json:"co2"`
+	SO2   float64 `json:"so2"`
+	H2O   float64 `json:"h2o"`
+	N2    float64 `json:"n2"`
+	O2    float64 `json:"o2"`
+	Total float64 `json:"total"`
+}
+
+// Dry - об'єм сухих продуктів згоряння (без водяної пари)
+func (g FlueGas) Dry() float64 {
+	return g.Total - g.H2O
+}
+
+// Percent - об'ємний склад продуктів згоряння (%)
+func (g FlueGas) Percent() FlueGas {
+	k := 100 / g.Total
+	return FlueGas{
+		CO2:   g.CO2 * k,
+		SO2:   g.SO2 * k,
+		H2O:   g.H2O * k,
+		N2:    g.N2 * k,
+		O2:    g.O2 * k,
+		Total: 100,
+	}
+}
+
+// Combustion - результат розрахунку горіння 1 кг палива
+type Combustion struct {
+	Alpha          float64 `json:"alpha"`          // Коефіцієнт надлишку повітря
+	TheoreticalAir float64 `json:"theoreticalAir"` // V⁰, м³/кг
+	ActualAir      float64 `json:"actualAir"`      // α·V⁰, м³/кг
+	ActualAirMass  float64 `json:"actualAirMass"`  // кг/кг
+	Theoretical    FlueGas `json:"theoretical"`    // Продукти згоряння при α = 1
+	Gas            FlueGas `json:"gas"`            // Продукти згоряння при заданому α
+	GasPercent     FlueGas `json:"gasPercent"`     // Об'ємний склад при заданому α (%)
+	DryGas         float64 `json:"dryGas"`         // Сухі продукти згоряння, м³/кг
+}
+
+// Combust - теоретична та дійсна кількість повітря і об'єми продуктів згоряння
+// для робочої маси p при коефіцієнті надлишку повітря alpha
+func Combust(p Composition, alpha float64) (Combustion, error) {
+	if alpha < 1 {
+		return Combustion{}, fmt.Errorf("коефіцієнт надлишку повітря має бути не менше 1")
+	}
+
+	// Теоретично необхідна кількість повітря
+	// V⁰ = 0.0889(C + 0.375S) + 0.265H - 0.0333O
+	v0 := 0.0889*(p.C+0.375*p.S) + 0.265*p.H - 0.0333*p.O
+
+	gas := func(a float64) FlueGas {
+		g := FlueGas{
+			CO2: 1.866 * p.C / 100,
+			SO2: 0.7 * p.S / 100,
+			// Водяна пара з водню, вологи палива та вологи повітря
+			H2O: 0.111*p.H + 0.0124*p.W + 0.0161*a*v0,
+			// Азот повітря та палива
+			N2: 0.79*a*v0 + 0.8*p.N/100,
+			// Надлишковий кисень
+			O2: 0.21 * (a - 1) * v0,
+		}
+		g.Total = g.CO2 + g.SO2 + g.H2O + g.N2 + g.O2
+		return g
+	}
+
+	actual := gas(alpha)
+	return Combustion{
+		Alpha:          alpha,
+		TheoreticalAir: v0,
+		ActualAir:      alpha * v0,
+		ActualAirMass:  alpha * v0 * AirDensity,
+		Theoretical:    gas(1),
+		Gas:            actual,
+		GasPercent:     actual.Percent(),
+		DryGas:         actual.Dry(),
+	}, nil
+}
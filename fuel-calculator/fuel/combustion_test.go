@@ -0,0 +1,51 @@
+package fuel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCombust(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		fuel      Composition
+		alpha     float64
+		v0, mass  float64
+		h2o, n2   float64
+		o2, total float64
+		dry, co2  float64 // dry gas, m³/kg; CO2 share, %
+	}{
+		{"gas coal, α=1", gasCoal, 1, 5.82782, 7.535371, 0.639628, 4.611978, 0, 6.304038, 5.66441, 16.339242},
+		{"gas coal, α=1.2", gasCoal, 1.2, 5.82782, 9.042446, 0.658393, 5.532773, 0.244768, 7.488367, 6.829974, 13.755095},
+		{"anthracite, α=1.4", anthracite, 1.4, 6.003204, 10.866999, 0.373912, 6.644343, 0.504269, 8.724933, 8.35102, 13.644896},
+	} {
+		res, err := Combust(c.fuel, c.alpha)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		for _, v := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"V0", res.TheoreticalAir, c.v0},
+			{"air", res.ActualAir, c.alpha * c.v0},
+			{"air mass", res.ActualAirMass, c.mass},
+			{"CO2", res.Gas.CO2, 1.866 * c.fuel.C / 100},
+			{"H2O", res.Gas.H2O, c.h2o},
+			{"N2", res.Gas.N2, c.n2},
+			{"O2", res.Gas.O2, c.o2},
+			{"total", res.Gas.Total, c.total},
+			{"dry", res.DryGas, c.dry},
+			{"CO2 %", res.GasPercent.CO2, c.co2},
+			{"total %", res.GasPercent.Total, 100},
+		} {
+			if math.Abs(v.got-v.want) > 1e-6 {
+				t.Errorf("%s: %s = %v, want %v", c.name, v.name, v.got, v.want)
+			}
+		}
+	}
+
+	if _, err := Combust(gasCoal, 0.9); err == nil {
+		t.Error("α < 1 accepted")
+	}
+}
@@ -0,0 +1,78 @@
+package fuel
+
+// StandardFuelLHV - нижча теплота умовного палива: 7000 ккал/кг = 29.3076 МДж/кг
+const StandardFuelLHV = 7000 * 4.1868 / 1000
+
+// HoursPerYear - тривалість роботи котла за замовчуванням (год/рік)
+const HoursPerYear = 8760
+
+// ConsumptionInput - параметри котла для розрахунку витрати палива
+type ConsumptionInput struct {
+	Output     float64 `json:"output"`     // Теплова потужність котла, МВт
+	Efficiency float64 `json:"efficiency"` // ККД котла (брутто), %
+	Hours      float64 `json:"hours"`      // Години роботи на рік (0 - HoursPerYear)
+	Price      float64 `json:"price"`      // Ціна палива, грн/т (0 - вартість не рахується)
+	LHV        float64 `json:"lhv"`        // Нижча теплота робочої маси, МДж/кг
+}
+
+// Validate - перевіряє параметри котла
+func (in ConsumptionInput) Validate() error {
+	var errs ValidationErrors
+	if in.Output <= 0 {
+		errs.add("output", "потужність має бути додатною")
+	}
+	if in.Efficiency <= 0 || in.Efficiency > 100 {
+		errs.add("efficiency", "ККД має бути в межах (0, 100] %%")
+	}
+	if in.Hours < 0 || in.Hours > HoursPerYear {
+		errs.add("hours", "кількість годин має бути в межах [0, %d]", HoursPerYear)
+	}
+	if in.Price < 0 {
+		errs.add("price", "ціна не може бути від'ємною")
+	}
+	if in.LHV <= 0 {
+		errs.add("lhv", "нижча теплота має бути додатною")
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Consumption - витрата натурального та умовного палива і її вартість
+type Consumption struct {
+	Hours          float64 `json:"hours"`          // Години роботи на рік
+	Hourly         float64 `json:"hourly"`         // Натуральне паливо, т/год
+	Annual         float64 `json:"annual"`         // Натуральне паливо, т/рік
+	Equivalent     float64 `json:"equivalent"`     // Калорійний еквівалент Qi / 29.31
+	StandardHourly float64 `json:"standardHourly"` // Умовне паливо, т у.п./год
+	StandardAnnual float64 `json:"standardAnnual"` // Умовне паливо, т у.п./рік
+	CostHourly     float64 `json:"costHourly"`     // грн/год
+	CostAnnual     float64 `json:"costAnnual"`     // грн/рік
+}
+
+// FuelConsumption - витрата палива котлом: B = Q / (η·Qi)
+func FuelConsumption(in ConsumptionInput) (Consumption, error) {
+	if err := in.Validate(); err != nil {
+		return Consumption{}, err
+	}
+	hours := in.Hours
+	if hours == 0 {
+		hours = HoursPerYear
+	}
+
+	// МВт = МДж/с, тож Q / (η·Qi) дає кг/с; * 3.6 - т/год
+	hourly := in.Output / (in.Efficiency / 100 * in.LHV) * 3.6
+	equivalent := in.LHV / StandardFuelLHV
+
+	return Consumption{
+		Hours:          hours,
+		Hourly:         hourly,
+		Annual:         hourly * hours,
+		Equivalent:     equivalent,
+		StandardHourly: hourly * equivalent,
+		StandardAnnual: hourly * hours * equivalent,
+		CostHourly:     hourly * in.Price,
+		CostAnnual:     hourly * hours * in.Price,
+	}, nil
+}
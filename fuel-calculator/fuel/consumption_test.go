@@ -0,0 +1,71 @@
+package fuel
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestFuelConsumption(t *testing.T) {
+	// B = Q / (η·Qi) · 3.6 т/год, умовне паливо - B·Qi/29.3076
+	for _, c := range []struct {
+		name                             string
+		in                               ConsumptionInput
+		hours, hourly, annual            float64
+		equivalent, stdHourly, stdAnnual float64
+		costHourly, costAnnual           float64
+	}{
+		{"coal, default hours", ConsumptionInput{Output: 100, Efficiency: 90, Price: 4000, LHV: 20.47},
+			8760, 19.540791, 171177.332682, 0.698454, 13.648337, 119559.431683, 78163.165608, 684709330.727895},
+		{"mazut, 6000 h", ConsumptionInput{Output: 50, Efficiency: 85, Hours: 6000, Price: 25000, LHV: 40.4},
+			6000, 5.241701, 31450.203844, 1.378482, 7.225590, 43353.540900, 131042.516016, 786255096.097845},
+		{"no price", ConsumptionInput{Output: 50, Efficiency: 85, Hours: 6000, LHV: 40.4},
+			6000, 5.241701, 31450.203844, 1.378482, 7.225590, 43353.540900, 0, 0},
+	} {
+		res, err := FuelConsumption(c.in)
+		if err != nil {
+			t.Fatalf("%s: %v", c.name, err)
+		}
+		for _, v := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"hours", res.Hours, c.hours},
+			{"hourly", res.Hourly, c.hourly},
+			{"annual", res.Annual, c.annual},
+			{"equivalent", res.Equivalent, c.equivalent},
+			{"standard hourly", res.StandardHourly, c.stdHourly},
+			{"standard annual", res.StandardAnnual, c.stdAnnual},
+			{"cost hourly", res.CostHourly, c.costHourly},
+			{"cost annual", res.CostAnnual, c.costAnnual},
+		} {
+			if math.Abs(v.got-v.want) > 1e-6 {
+				t.Errorf("%s: %s = %.6f, want %.6f", c.name, v.name, v.got, v.want)
+			}
+		}
+	}
+}
+
+func TestFuelConsumptionErrors(t *testing.T) {
+	valid := ConsumptionInput{Output: 100, Efficiency: 90, LHV: 20.47}
+	for _, c := range []struct {
+		field  string
+		change func(*ConsumptionInput)
+	}{
+		{"output", func(in *ConsumptionInput) { in.Output = 0 }},
+		{"efficiency", func(in *ConsumptionInput) { in.Efficiency = 0 }},
+		{"efficiency", func(in *ConsumptionInput) { in.Efficiency = 100.1 }},
+		{"hours", func(in *ConsumptionInput) { in.Hours = -1 }},
+		{"hours", func(in *ConsumptionInput) { in.Hours = HoursPerYear + 1 }},
+		{"price", func(in *ConsumptionInput) { in.Price = -1 }},
+		{"lhv", func(in *ConsumptionInput) { in.LHV = 0 }},
+	} {
+		in := valid
+		c.change(&in)
+		_, err := FuelConsumption(in)
+		var errs ValidationErrors
+		if !errors.As(err, &errs) || len(errs) != 1 || errs[0].Field != c.field {
+			t.Errorf("%+v: error %v, want one on %s", in, err, c.field)
+		}
+	}
+}
@@ -0,0 +1,131 @@
+// Пакет fuel містить розрахунки складу та теплоти згоряння палива,
+// які раніше жили безпосередньо в HTTP-обробниках fuel-calculator.
+// Усі величини складу задаються у відсотках за масою, теплота — в МДж/кг.
+package fuel
+
+// Composition - елементарний склад палива на певній масі (%)
+type Composition struct {
+	H float64 `json:"h"` // Водень
+	C float64 `json:"c"` // Вуглець
+	S float64 `json:"s"` // Сірка
+	N float64 `json:"n"` // Азот
+	O float64 `json:"o"` // Кисень
+	W float64 `json:"w"` // Волога
+	A float64 `json:"a"` // Зола
+}
+
+// Scale - множить усі компоненти складу на коефіцієнт k
+func (c Composition) Scale(k float64) Composition {
+	return Composition{
+		H: c.H * k,
+		C: c.C * k,
+		S: c.S * k,
+		N: c.N * k,
+		O: c.O * k,
+		W: c.W * k,
+		A: c.A * k,
+	}
+}
+
+// Sum - сума всіх компонентів складу (%)
+func (c Composition) Sum() float64 {
+	return c.H + c.C + c.S + c.N + c.O + c.W + c.A
+}
+
+// Analysis - результат перерахунку робочої маси (Завдання 1)
+type Analysis struct {
+	Kpc          float64         `json:"kpc"` // Коеф. переходу роб. -> суха
+	Krg          float64         `json:"krg"` // Коеф. переходу роб. -> горюча
+	Working      Composition     `json:"working"`
+	Dry          Composition     `json:"dry"`
+	Combustible  Composition     `json:"combustible"`
+	HeatingValue HeatingValues   `json:"heatingValue"` // За обраною формулою
+	Comparison   []HeatingValues `json:"comparison"`   // За всіма формулами для порівняння
+	Estimated    bool            `json:"estimated"`    // Склад оцінено, а не виміряно (див. EstimateUltimate)
+}
+
+// Analyze - перерахунок робочої маси на суху та горючу і розрахунок теплоти згоряння за Менделєєвим
+func Analyze(p Composition) Analysis {
+	res, _ := AnalyzeWith(p, Mendeleev)
+	return res
+}
+
+// AnalyzeWith - те саме, що Analyze, але теплота згоряння рахується за формулою corr
+func AnalyzeWith(p Composition, corr Correlation) (Analysis, error) {
+	hv, err := HeatingValuesFor(p, corr)
+	if err != nil {
+		return Analysis{}, err
+	}
+	dry, combustible := dryAndCombustible(p)
+
+	return Analysis{
+		// kpc (коефіцієнт для сухої маси) = 100 / (100 - wᵖ)
+		Kpc: 100 / (100 - p.W),
+		// krg (коефіцієнт для горючої маси) = 100 / (100 - wᵖ - aᵖ)
+		Krg:          100 / (100 - p.W - p.A),
+		Working:      p,
+		Dry:          dry,
+		Combustible:  combustible,
+		HeatingValue: hv,
+		Comparison:   CompareHeatingValues(p),
+	}, nil
+}
+
+// dryAndCombustible - перерахунок робочої маси на суху (без вологи) та горючу (без вологи та золи)
+func dryAndCombustible(p Composition) (dry, combustible Composition) {
+	dry = p.Scale(100 / (100 - p.W))
+	dry.W = 0
+
+	combustible = p.Scale(100 / (100 - p.W - p.A))
+	combustible.W = 0
+	combustible.A = 0
+	return dry, combustible
+}
+
+// LowerHeatingValue - нижча теплота згоряння робочої маси за формулою Менделєєва (МДж/кг)
+func LowerHeatingValue(p Composition) float64 {
+	return (339*p.C + 1030*p.H - 108.8*(p.O-p.S) - 25*p.W) / 1000
+}
+
+// MazutInput - вхідні дані для мазуту (Завдання 2)
+type MazutInput struct {
+	C  float64 `json:"cg"` // Вуглець на горючу масу (%)
+	H  float64 `json:"hg"` // Водень на горючу масу (%)
+	O  float64 `json:"og"` // Кисень на горючу масу (%)
+	S  float64 `json:"sg"` // Сірка на горючу масу (%)
+	Qi float64 `json:"qi"` // Нижча теплота горючої маси (МДж/кг)
+	V  float64 `json:"vg"` // Ванадій (мг/кг)
+	W  float64 `json:"wg"` // Волога (%)
+	A  float64 `json:"ag"` // Зола на суху масу (%)
+}
+
+// MazutAnalysis - результат перерахунку мазуту на робочу масу
+type MazutAnalysis struct {
+	Working Composition `json:"working"`
+	V       float64     `json:"v"`   // Ванадій на робочу масу (мг/кг)
+	Qri     float64     `json:"qri"` // Нижча теплота робочої маси (МДж/кг)
+
+	Assessment MazutAssessment `json:"assessment"` // Викиди та корозійна небезпека
+}
+
+// AnalyzeMazut - перерахунок елементарного складу мазуту з горючої на робочу масу
+func AnalyzeMazut(in MazutInput) MazutAnalysis {
+	k := (100 - in.W - in.A) / 100
+	ap := in.A * (100 - in.W) / 100
+
+	res := MazutAnalysis{
+		Working: Composition{
+			C: in.C * k,
+			H: in.H * k,
+			O: in.O * k,
+			S: in.S * k,
+			W: in.W,
+			A: ap,
+		},
+		V: in.V * (100 - in.W) / 100,
+		// Нижча теплота згоряння (з поправкою на робочу масу)
+		Qri: in.Qi*(100-in.W-ap)/100 - 0.025*in.W,
+	}
+	res.Assessment = AssessMazut(res.Working.S, res.V, res.Qri)
+	return res
+}
@@ -0,0 +1,65 @@
+package fuel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestAnalyze(t *testing.T) {
+	res := Analyze(gasCoal)
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"Kpc", res.Kpc, 1.111111},
+		{"Krg", res.Krg, 1.449275},
+		{"dry C", res.Dry.C, 61.333333},
+		{"dry W", res.Dry.W, 0},
+		{"dry sum", res.Dry.Sum(), 100},
+		{"combustible H", res.Combustible.H, 5.507246},
+		{"combustible C", res.Combustible.C, 80},
+		{"combustible A", res.Combustible.A, 0},
+		{"combustible sum", res.Combustible.Sum(), 100},
+		{"working Qs", res.HeatingValue.Working.Higher, 23.19892},
+		{"working Qi", res.HeatingValue.Working.Lower, 22.09392},
+		{"dry Qi", res.HeatingValue.Dry.Lower, 24.826578},
+		{"combustible Qi", res.HeatingValue.Combustible.Lower, 32.382493},
+	} {
+		if math.Abs(c.got-c.want) > 1e-6 {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	if res.Working != gasCoal {
+		t.Errorf("Working = %+v, want the input", res.Working)
+	}
+	if len(res.Comparison) != len(Correlations) {
+		t.Errorf("Comparison has %d formulas, want %d", len(res.Comparison), len(Correlations))
+	}
+}
+
+func TestAnalyzeWith(t *testing.T) {
+	for _, c := range []struct {
+		corr   Correlation
+		higher float64
+	}{
+		{Mendeleev, 23.19892},
+		{Dulong, 23.412825},
+	} {
+		res, err := AnalyzeWith(gasCoal, c.corr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if math.Abs(res.HeatingValue.Working.Higher-c.higher) > 1e-6 {
+			t.Errorf("%s: Qs = %v, want %v", c.corr, res.HeatingValue.Working.Higher, c.higher)
+		}
+		// Qi = Qs - 0.025(9H + W) whatever the formula
+		wantLower := c.higher - LatentHeat*(9*gasCoal.H+gasCoal.W)
+		if math.Abs(res.HeatingValue.Working.Lower-wantLower) > 1e-6 {
+			t.Errorf("%s: Qi = %v, want %v", c.corr, res.HeatingValue.Working.Lower, wantLower)
+		}
+	}
+
+	if _, err := AnalyzeWith(gasCoal, "unknown"); err == nil {
+		t.Error("unknown correlation accepted")
+	}
+}
@@ -0,0 +1,112 @@
+package fuel
+
+import "fmt"
+
+// LatentHeat - теплота пароутворення води, МДж/кг на 1 % вологи або 1/9 % водню
+const LatentHeat = 0.025
+
+// Correlation - емпірична формула для вищої теплоти згоряння
+type Correlation string
+
+const (
+	Mendeleev        Correlation = "mendeleev"
+	Dulong           Correlation = "dulong"
+	Boie             Correlation = "boie"
+	ChanniwalaParikh Correlation = "channiwala-parikh"
+)
+
+// Correlations - усі доступні формули у порядку виводу
+var Correlations = []Correlation{Mendeleev, Dulong, Boie, ChanniwalaParikh}
+
+// correlation - назва та формула вищої теплоти (МДж/кг) для складу на будь-якій масі
+type correlation struct {
+	name string
+	hhv  func(c Composition) float64
+}
+
+var correlations = map[Correlation]correlation{
+	// Менделєєв: Qs = 339C + 1030H - 108.8(O - S) кДж/кг + теплота конденсації вологи з водню
+	Mendeleev: {"Менделєєв", func(c Composition) float64 {
+		return (339*c.C+1030*c.H-108.8*(c.O-c.S))/1000 + LatentHeat*9*c.H
+	}},
+	// Дюлонг: Qs = 33.83C + 144.3(H - O/8) + 9.42S (частки маси)
+	Dulong: {"Дюлонг", func(c Composition) float64 {
+		return 0.3383*c.C + 1.443*(c.H-c.O/8) + 0.0942*c.S
+	}},
+	// Буа (Boie): Qs = 35.16C + 116.225H - 11.09O + 6.28N + 10.465S (частки маси)
+	Boie: {"Буа", func(c Composition) float64 {
+		return 0.3516*c.C + 1.16225*c.H - 0.1109*c.O + 0.0628*c.N + 0.10465*c.S
+	}},
+	// Чанівала-Паріх: Qs = 34.91C + 117.83H + 10.05S - 10.34O - 1.51N - 2.11A (частки маси)
+	ChanniwalaParikh: {"Чанівала-Паріх", func(c Composition) float64 {
+		return 0.3491*c.C + 1.1783*c.H + 0.1005*c.S - 0.1034*c.O - 0.0151*c.N - 0.0211*c.A
+	}},
+}
+
+// Name - українська назва формули
+func (c Correlation) Name() string {
+	if corr, ok := correlations[c]; ok {
+		return corr.name
+	}
+	return string(c)
+}
+
+// ParseCorrelation - перевіряє назву формули; порожній рядок означає формулу Менделєєва
+func ParseCorrelation(s string) (Correlation, error) {
+	if s == "" {
+		return Mendeleev, nil
+	}
+	c := Correlation(s)
+	if _, ok := correlations[c]; !ok {
+		return "", fmt.Errorf("невідома формула теплоти згоряння %q", s)
+	}
+	return c, nil
+}
+
+// HeatingValue - вища та нижча теплота згоряння на одній масі (МДж/кг)
+type HeatingValue struct {
+	Higher float64 `json:"higher"`
+	Lower  float64 `json:"lower"`
+}
+
+// HeatingValues - теплота згоряння за однією формулою на робочій, сухій та горючій масах
+type HeatingValues struct {
+	Correlation Correlation  `json:"correlation"`
+	Working     HeatingValue `json:"working"`
+	Dry         HeatingValue `json:"dry"`
+	Combustible HeatingValue `json:"combustible"`
+}
+
+// heatingValue - вища теплота за формулою та нижча як Qi = Qs - 0.025(9H + W)
+func heatingValue(c Composition, hhv func(Composition) float64) HeatingValue {
+	qs := hhv(c)
+	return HeatingValue{
+		Higher: qs,
+		Lower:  qs - LatentHeat*(9*c.H+c.W),
+	}
+}
+
+// HeatingValuesFor - теплота згоряння за формулою corr для робочої маси p та її сухої й горючої мас
+func HeatingValuesFor(p Composition, corr Correlation) (HeatingValues, error) {
+	f, ok := correlations[corr]
+	if !ok {
+		return HeatingValues{}, fmt.Errorf("невідома формула теплоти згоряння %q", corr)
+	}
+	dry, combustible := dryAndCombustible(p)
+	return HeatingValues{
+		Correlation: corr,
+		Working:     heatingValue(p, f.hhv),
+		Dry:         heatingValue(dry, f.hhv),
+		Combustible: heatingValue(combustible, f.hhv),
+	}, nil
+}
+
+// CompareHeatingValues - теплота згоряння робочої маси p за всіма формулами
+func CompareHeatingValues(p Composition) []HeatingValues {
+	res := make([]HeatingValues, 0, len(Correlations))
+	for _, corr := range Correlations {
+		hv, _ := HeatingValuesFor(p, corr)
+		res = append(res, hv)
+	}
+	return res
+}
@@ -0,0 +1,245 @@
+package fuel
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Kind - тип запису в бібліотеці: визначає, в яке завдання завантажується паливо
+type Kind string
+
+const (
+	Solid Kind = "solid" // Тверде паливо, робоча маса (Завдання 1)
+	Mazut Kind = "mazut" // Мазут, горюча маса (Завдання 2)
+)
+
+// Entry - іменоване паливо з бібліотеки
+type Entry struct {
+	ID      string       `json:"id"`
+	Name    string       `json:"name"`
+	Kind    Kind         `json:"kind"`
+	Builtin bool         `json:"builtin"`           // Вбудовані записи не можна змінювати
+	Working *Composition `json:"working,omitempty"` // Для Kind == Solid
+	Mazut   *MazutInput  `json:"mazut,omitempty"`   // Для Kind == Mazut
+}
+
+// Validate - перевіряє назву та склад запису відповідно до його типу
+func (e Entry) Validate() error {
+	if strings.TrimSpace(e.Name) == "" {
+		return ValidationErrors{{Field: "name", Message: "назва обов'язкова"}}
+	}
+	switch e.Kind {
+	case Solid:
+		if e.Working == nil {
+			return ValidationErrors{{Field: "working", Message: "склад робочої маси обов'язковий"}}
+		}
+		return e.Working.Validate()
+	case Mazut:
+		if e.Mazut == nil {
+			return ValidationErrors{{Field: "mazut", Message: "склад мазуту обов'язковий"}}
+		}
+		return e.Mazut.Validate()
+	default:
+		return ValidationErrors{{Field: "kind", Message: fmt.Sprintf("невідомий тип палива %q", e.Kind)}}
+	}
+}
+
+// Помилки бібліотеки
+var (
+	ErrNotFound = errors.New("паливо не знайдено")
+	ErrBuiltin  = errors.New("вбудоване паливо не можна змінювати")
+)
+
+// builtinFuels - типові марки вугілля та мазуту
+var builtinFuels = []Entry{
+	{ID: "coal-brown", Name: "Буре вугілля", Kind: Solid,
+		Working: &Composition{H: 1.9, C: 21.1, S: 2.6, N: 0.2, O: 7.1, W: 53, A: 14.1}},
+	{ID: "coal-gas", Name: "Донецьке газове вугілля (Г)", Kind: Solid,
+		Working: &Composition{H: 3.8, C: 55.2, S: 3.2, N: 1.0, O: 5.8, W: 10, A: 21.0}},
+	{ID: "coal-anthracite", Name: "Антрацитовий штиб (АШ)", Kind: Solid,
+		Working: &Composition{H: 1.2, C: 63.8, S: 1.7, N: 0.6, O: 1.3, W: 8.5, A: 22.9}},
+	{ID: "mazut-40", Name: "Мазут М40 (вид 5, S 2.5 %)", Kind: Mazut,
+		Mazut: &MazutInput{C: 85.5, H: 11.2, O: 0.8, S: 2.5, Qi: 40.40, V: 333.3, W: 2, A: 0.15}},
+	{ID: "mazut-100", Name: "Мазут М100 (високосірчистий, вид 7)", Kind: Mazut,
+		Mazut: &MazutInput{C: 84.65, H: 11.7, O: 0.3, S: 3.35, Qi: 39.80, V: 200, W: 2, A: 0.1}},
+}
+
+// libraryFile - вміст файлу бібліотеки (лише записи користувача)
+type libraryFile struct {
+	NextID  int     `json:"nextId"`
+	Entries []Entry `json:"entries"`
+}
+
+// Library - бібліотека палив: вбудовані записи плюс записи користувача у JSON-файлі
+type Library struct {
+	mu   sync.Mutex
+	path string
+	data libraryFile
+}
+
+// OpenLibrary - відкриває бібліотеку з файлу path; якщо файлу ще немає, бібліотека порожня
+func OpenLibrary(path string) (*Library, error) {
+	l := &Library{path: path, data: libraryFile{NextID: 1}}
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(raw, &l.data); err != nil {
+		return nil, fmt.Errorf("файл бібліотеки %s: %w", path, err)
+	}
+	return l, nil
+}
+
+// List - усі записи: спочатку вбудовані, далі записи користувача за назвою
+func (l *Library) List() []Entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	user := append([]Entry(nil), l.data.Entries...)
+	sort.Slice(user, func(i, j int) bool { return user[i].Name < user[j].Name })
+
+	res := make([]Entry, 0, len(builtinFuels)+len(user))
+	for _, e := range builtinFuels {
+		e.Builtin = true
+		res = append(res, e)
+	}
+	return append(res, user...)
+}
+
+// Get - запис за ідентифікатором
+func (l *Library) Get(id string) (Entry, error) {
+	for _, e := range builtinFuels {
+		if e.ID == id {
+			e.Builtin = true
+			return e, nil
+		}
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if i := l.index(id); i >= 0 {
+		return l.data.Entries[i], nil
+	}
+	return Entry{}, ErrNotFound
+}
+
+// Create - додає новий запис користувача і повертає його з присвоєним ID
+func (l *Library) Create(e Entry) (Entry, error) {
+	if err := e.Validate(); err != nil {
+		return Entry{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	e.ID = "user-" + strconv.Itoa(l.data.NextID)
+	e.Builtin = false
+	data := libraryFile{NextID: l.data.NextID + 1, Entries: append(l.entries(), e)}
+	if err := l.commit(data); err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// Update - замінює запис користувача з ідентифікатором id
+func (l *Library) Update(id string, e Entry) (Entry, error) {
+	if isBuiltin(id) {
+		return Entry{}, ErrBuiltin
+	}
+	if err := e.Validate(); err != nil {
+		return Entry{}, err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i := l.index(id)
+	if i < 0 {
+		return Entry{}, ErrNotFound
+	}
+	e.ID = id
+	e.Builtin = false
+	data := libraryFile{NextID: l.data.NextID, Entries: l.entries()}
+	data.Entries[i] = e
+	if err := l.commit(data); err != nil {
+		return Entry{}, err
+	}
+	return e, nil
+}
+
+// Delete - видаляє запис користувача
+func (l *Library) Delete(id string) error {
+	if isBuiltin(id) {
+		return ErrBuiltin
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	i := l.index(id)
+	if i < 0 {
+		return ErrNotFound
+	}
+	entries := l.entries()
+	return l.commit(libraryFile{NextID: l.data.NextID, Entries: append(entries[:i], entries[i+1:]...)})
+}
+
+// index - позиція запису користувача або -1 (викликати під l.mu)
+func (l *Library) index(id string) int {
+	for i, e := range l.data.Entries {
+		if e.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// entries - копія записів користувача, яку можна змінювати (викликати під l.mu)
+func (l *Library) entries() []Entry {
+	return append([]Entry(nil), l.data.Entries...)
+}
+
+// commit - записує data у файл через тимчасовий файл і лише після успішного запису
+// замінює нею вміст бібліотеки в пам'яті (викликати під l.mu)
+func (l *Library) commit(data libraryFile) error {
+	raw, err := json.MarshalIndent(data, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := l.path + ".tmp"
+	if err := os.WriteFile(tmp, raw, 0o644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, l.path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	l.data = data
+	return nil
+}
+
+func isBuiltin(id string) bool {
+	for _, e := range builtinFuels {
+		if e.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// WorkingComposition - склад робочої маси запису (для мазуту - перерахований з горючої маси)
+func (e Entry) WorkingComposition() Composition {
+	switch {
+	case e.Working != nil:
+		return *e.Working
+	case e.Mazut != nil:
+		return AnalyzeMazut(*e.Mazut).Working
+	}
+	return Composition{}
+}
@@ -0,0 +1,93 @@
+package fuel
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func testEntry(name string) Entry {
+	return Entry{Name: name, Kind: Solid,
+		Working: &Composition{H: 3.8, C: 55.2, S: 3.2, N: 1.0, O: 5.8, W: 10, A: 21.0}}
+}
+
+func TestLibraryCRUD(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "fuels.json")
+	l, err := OpenLibrary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	e, err := l.Create(testEntry("Вугілля А"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.ID != "user-1" {
+		t.Errorf("ID = %q, want user-1", e.ID)
+	}
+	if _, err := l.Update(e.ID, testEntry("Вугілля Б")); err != nil {
+		t.Fatal(err)
+	}
+
+	// The file must hold the same state as memory
+	reopened, err := OpenLibrary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := reopened.Get(e.ID)
+	if err != nil || got.Name != "Вугілля Б" {
+		t.Errorf("reopened Get = %+v, %v", got, err)
+	}
+
+	if err := l.Delete(e.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := l.Get(e.ID); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after Delete: %v, want ErrNotFound", err)
+	}
+	if _, err := l.Update("coal-gas", testEntry("x")); !errors.Is(err, ErrBuiltin) {
+		t.Errorf("Update builtin: %v, want ErrBuiltin", err)
+	}
+}
+
+func TestLibraryRollbackOnWriteError(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "fuels.json")
+	l, err := OpenLibrary(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	e, err := l.Create(testEntry("Вугілля А"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Make the data file unwritable by replacing the directory
+	l.path = filepath.Join(dir, "missing", "fuels.json")
+	before := len(l.List())
+
+	if _, err := l.Create(testEntry("Вугілля В")); err == nil {
+		t.Fatal("Create: expected a write error")
+	}
+	if _, err := l.Update(e.ID, testEntry("Вугілля Б")); err == nil {
+		t.Fatal("Update: expected a write error")
+	}
+	if err := l.Delete(e.ID); err == nil {
+		t.Fatal("Delete: expected a write error")
+	}
+
+	if n := len(l.List()); n != before {
+		t.Errorf("List has %d entries after failed writes, want %d", n, before)
+	}
+	got, err := l.Get(e.ID)
+	if err != nil || got.Name != "Вугілля А" {
+		t.Errorf("Get = %+v, %v; want the unchanged entry", got, err)
+	}
+	if l.data.NextID != 2 {
+		t.Errorf("NextID = %d, want 2", l.data.NextID)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("original file: %v", err)
+	}
+}
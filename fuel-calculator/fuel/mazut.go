@@ -0,0 +1,96 @@
+package fuel
+
+import "fmt"
+
+// Межі для оцінки мазуту
+const (
+	// Вміст ванадію, вище якого можлива високотемпературна ванадієва корозія поверхонь нагріву (мг/кг)
+	VanadiumCorrosionLimit = 50.0
+	// Вміст сірки, вище якого різко зростає ризик низькотемпературної сірчанокислотної корозії (%)
+	SulfurCorrosionLimit = 2.0
+)
+
+// Молярні маси (г/моль)
+const (
+	molarV    = 50.94
+	molarV2O5 = 181.88
+	molarS    = 32.06
+	molarSO2  = 64.06
+)
+
+// gradeLimit - клас палива з верхньою межею показника
+type gradeLimit struct {
+	max  float64
+	name string
+}
+
+// sulfurGrades - класифікація мазуту за масовою часткою сірки (ГОСТ 10585), верхні межі у %
+var sulfurGrades = []gradeLimit{
+	{0.5, "вид 1 (малосірчистий, S ≤ 0.5 %)"},
+	{1.0, "вид 2 (S ≤ 1.0 %)"},
+	{1.5, "вид 3 (S ≤ 1.5 %)"},
+	{2.0, "вид 4 (сірчистий, S ≤ 2.0 %)"},
+	{2.5, "вид 5 (S ≤ 2.5 %)"},
+	{3.0, "вид 6 (S ≤ 3.0 %)"},
+	{3.5, "вид 7 (високосірчистий, S ≤ 3.5 %)"},
+}
+
+// vanadiumGrades - класифікація за вмістом ванадію (межі залишкових палив ISO 8217), мг/кг
+var vanadiumGrades = []gradeLimit{
+	{50, "низький (V ≤ 50 мг/кг)"},
+	{150, "помірний (V ≤ 150 мг/кг)"},
+	{350, "високий (V ≤ 350 мг/кг)"},
+	{450, "дуже високий (V ≤ 450 мг/кг)"},
+}
+
+// MazutAssessment - оцінка викидів та корозійної небезпеки мазуту на робочу масу
+type MazutAssessment struct {
+	V2O5          float64  `json:"v2o5"`          // Потенційний викид V2O5, г/т палива
+	SO2           float64  `json:"so2"`           // Потенційний викид SO2, кг/т палива
+	SO2PerGJ      float64  `json:"so2PerGJ"`      // Потенційний викид SO2, г/ГДж
+	SulfurGrade   string   `json:"sulfurGrade"`   // Клас за сіркою
+	VanadiumGrade string   `json:"vanadiumGrade"` // Клас за ванадієм
+	Warnings      []string `json:"warnings"`      // Попередження про корозійну небезпеку
+}
+
+// AssessMazut - викиди V2O5 та SO2 і класифікація мазуту за сіркою Sp (%), ванадієм Vp (мг/кг)
+// та нижчою теплотою Qri (МДж/кг) робочої маси
+func AssessMazut(sp, vp, qri float64) MazutAssessment {
+	// Уся сірка та ванадій переходять в оксиди: SO2 = S·64/32, V2O5 = V·182/(2·51)
+	so2 := sp * 10 * molarSO2 / molarS // % -> кг/т
+	res := MazutAssessment{
+		V2O5:          vp * molarV2O5 / (2 * molarV), // мг/кг = г/т
+		SO2:           so2,
+		SulfurGrade:   grade(sulfurGrades, sp, "поза класифікацією (S > 3.5 %)"),
+		VanadiumGrade: grade(vanadiumGrades, vp, "поза класифікацією (V > 450 мг/кг)"),
+	}
+	if qri > 0 {
+		res.SO2PerGJ = so2 / qri * 1000
+	}
+
+	if vp > VanadiumCorrosionLimit {
+		res.Warnings = append(res.Warnings, fmt.Sprintf(
+			"ванадій %.1f мг/кг > %.0f мг/кг: ризик високотемпературної ванадієвої корозії пароперегрівача, "+
+				"потрібні присадки (MgO) або обмеження температури металу", vp, VanadiumCorrosionLimit))
+	}
+	if sp > SulfurCorrosionLimit {
+		res.Warnings = append(res.Warnings, fmt.Sprintf(
+			"сірка %.2f %% > %.1f %%: ризик низькотемпературної сірчанокислотної корозії, "+
+				"температура відхідних газів має бути вище точки роси", sp, SulfurCorrosionLimit))
+	}
+	if vp > VanadiumCorrosionLimit && sp > SulfurCorrosionLimit {
+		res.Warnings = append(res.Warnings,
+			"поєднання ванадію та сірки прискорює утворення відкладень: експлуатація з мінімальним надлишком повітря")
+	}
+	return res
+}
+
+// grade - назва першого класу, верхня межа якого не менша за v
+func grade(grades []gradeLimit, v float64, above string) string {
+	for _, g := range grades {
+		if v <= g.max {
+			return g.name
+		}
+	}
+	return above
+}
@@ -0,0 +1,85 @@
+package fuel
+
+import (
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestAssessMazutEmissions(t *testing.T) {
+	// SO2 = S·10·64.06/32.06 кг/т, V2O5 = V·181.88/(2·50.94) г/т, на ГДж: SO2/Qri·1000
+	for _, c := range []struct {
+		name                string
+		sp, vp, qri         float64
+		so2, v2o5, so2PerGJ float64
+	}{
+		{"1 % S, 100 mg/kg V", 1, 100, 40, 19.981285, 178.523753, 499.532127},
+		{"high-sulfur", 2.5, 200, 39.73, 49.953213, 357.047507, 1257.317209},
+		{"no heating value", 2.5, 200, 0, 49.953213, 357.047507, 0},
+		{"clean", 0, 0, 40, 0, 0, 0},
+	} {
+		res := AssessMazut(c.sp, c.vp, c.qri)
+		for _, v := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"SO2", res.SO2, c.so2},
+			{"V2O5", res.V2O5, c.v2o5},
+			{"SO2 per GJ", res.SO2PerGJ, c.so2PerGJ},
+		} {
+			if math.Abs(v.got-v.want) > 1e-6 {
+				t.Errorf("%s: %s = %.6f, want %.6f", c.name, v.name, v.got, v.want)
+			}
+		}
+	}
+}
+
+func TestAssessMazutGrades(t *testing.T) {
+	// Верхні межі класів включні
+	for _, c := range []struct {
+		sp, vp           float64
+		sulfur, vanadium string
+	}{
+		{0, 0, "вид 1", "низький"},
+		{0.5, 50, "вид 1", "низький"},
+		{0.51, 50.1, "вид 2", "помірний"},
+		{2.0, 150, "вид 4", "помірний"},
+		{2.01, 150.1, "вид 5", "високий"},
+		{3.0, 350, "вид 6", "високий"},
+		{3.5, 450, "вид 7", "дуже високий"},
+		{3.51, 450.1, "поза класифікацією", "поза класифікацією"},
+	} {
+		res := AssessMazut(c.sp, c.vp, 40)
+		if !strings.HasPrefix(res.SulfurGrade, c.sulfur) {
+			t.Errorf("S = %v: grade %q, want %q", c.sp, res.SulfurGrade, c.sulfur)
+		}
+		if !strings.HasPrefix(res.VanadiumGrade, c.vanadium) {
+			t.Errorf("V = %v: grade %q, want %q", c.vp, res.VanadiumGrade, c.vanadium)
+		}
+	}
+}
+
+func TestAssessMazutWarnings(t *testing.T) {
+	// Попередження лише за перевищення меж, не на самих межах
+	for _, c := range []struct {
+		name   string
+		sp, vp float64
+		want   []string
+	}{
+		{"at the limits", SulfurCorrosionLimit, VanadiumCorrosionLimit, nil},
+		{"vanadium", SulfurCorrosionLimit, 50.1, []string{"ванадій 50.1"}},
+		{"sulfur", 2.01, VanadiumCorrosionLimit, []string{"сірка 2.01"}},
+		{"both", 2.8, 120, []string{"ванадій 120.0", "сірка 2.80", "поєднання ванадію та сірки"}},
+	} {
+		res := AssessMazut(c.sp, c.vp, 40)
+		if len(res.Warnings) != len(c.want) {
+			t.Errorf("%s: warnings %q, want %d", c.name, res.Warnings, len(c.want))
+			continue
+		}
+		for i, w := range c.want {
+			if !strings.HasPrefix(res.Warnings[i], w) {
+				t.Errorf("%s: warning %d = %q, want it to start with %q", c.name, i, res.Warnings[i], w)
+			}
+		}
+	}
+}
@@ -0,0 +1,143 @@
+package fuel
+
+import (
+	"fmt"
+	"math"
+)
+
+// Proximate - технічний аналіз палива на робочу масу (%)
+type Proximate struct {
+	W  float64 `json:"w"`  // Волога
+	A  float64 `json:"a"`  // Зола
+	VM float64 `json:"vm"` // Леткі речовини
+	FC float64 `json:"fc"` // Зв'язаний вуглець (0 - визначається за різницею)
+	S  float64 `json:"s"`  // Сірка, якщо виміряна (необов'язково)
+	N  float64 `json:"n"`  // Азот, якщо виміряний (необов'язково)
+}
+
+// Validate - перевіряє технічний аналіз
+func (p Proximate) Validate() error {
+	var errs ValidationErrors
+	errs.moistureAndAsh("w", p.W, "a", p.A)
+	errs.percent("vm", p.VM)
+	errs.percent("fc", p.FC)
+	errs.percent("s", p.S)
+	errs.percent("n", p.N)
+	if p.FC == 0 {
+		if p.W+p.A+p.VM > 100+SumTolerance {
+			errs.add("vm", "сума вологи, золи та летких перевищує 100 %%")
+		}
+	} else {
+		errs.sum(p.W + p.A + p.VM + p.FC)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ProximateCorrelation - емпірична формула оцінки C, H, O за технічним аналізом
+type ProximateCorrelation string
+
+const (
+	Parikh ProximateCorrelation = "parikh" // Parikh, Channiwala, Ghosal (2007)
+	Shen   ProximateCorrelation = "shen"   // Shen, Zhu, Liu (2010)
+)
+
+// ProximateCorrelations - усі формули оцінки у порядку виводу
+var ProximateCorrelations = []ProximateCorrelation{Parikh, Shen}
+
+// proximateCorrelation - назва та формули для сухої маси: (FC, VM, A) -> (C, H, O)
+type proximateCorrelation struct {
+	name     string
+	estimate func(fc, vm, a float64) (c, h, o float64)
+}
+
+var proximateCorrelations = map[ProximateCorrelation]proximateCorrelation{
+	Parikh: {"Паріх", func(fc, vm, a float64) (float64, float64, float64) {
+		return 0.637*fc + 0.455*vm,
+			0.052*fc + 0.062*vm,
+			0.304*fc + 0.476*vm
+	}},
+	Shen: {"Шень", func(fc, vm, a float64) (float64, float64, float64) {
+		return 0.635*fc + 0.460*vm - 0.095*a,
+			0.059*fc + 0.060*vm + 0.010*a,
+			0.340*fc + 0.469*vm - 0.023*a
+	}},
+}
+
+// Name - назва формули
+func (c ProximateCorrelation) Name() string {
+	if corr, ok := proximateCorrelations[c]; ok {
+		return corr.name
+	}
+	return string(c)
+}
+
+// ParseProximateCorrelation - перевіряє назву формули; порожній рядок означає формулу Паріха
+func ParseProximateCorrelation(s string) (ProximateCorrelation, error) {
+	if s == "" {
+		return Parikh, nil
+	}
+	c := ProximateCorrelation(s)
+	if _, ok := proximateCorrelations[c]; !ok {
+		return "", fmt.Errorf("невідома формула оцінки складу %q", s)
+	}
+	return c, nil
+}
+
+// UltimateEstimate - елементарний склад, оцінений за технічним аналізом
+type UltimateEstimate struct {
+	Correlation ProximateCorrelation `json:"correlation"`
+	Estimated   bool                 `json:"estimated"` // Завжди true: склад не виміряний
+	FC          float64              `json:"fc"`        // Зв'язаний вуглець на робочу масу (з урахуванням різниці)
+	Raw         Composition          `json:"raw"`       // Суха маса безпосередньо за формулою
+	Scale       float64              `json:"scale"`     // Множник для C, H, O, щоб сума сухої маси дорівнювала 100 %
+	Dry         Composition          `json:"dry"`
+	Working     Composition          `json:"working"`
+}
+
+// EstimateUltimate - оцінює C, H, O за технічним аналізом, додає виміряні S та N
+// і нормує склад так, щоб сума дорівнювала 100 %
+func EstimateUltimate(p Proximate, corr ProximateCorrelation) (UltimateEstimate, error) {
+	f, ok := proximateCorrelations[corr]
+	if !ok {
+		return UltimateEstimate{}, fmt.Errorf("невідома формула оцінки складу %q", corr)
+	}
+	if err := p.Validate(); err != nil {
+		return UltimateEstimate{}, err
+	}
+
+	fc := p.FC
+	if fc == 0 {
+		// Допуск на суму може дати від'ємну різницю - такого вуглецю немає
+		fc = math.Max(0, 100-p.W-p.A-p.VM)
+	}
+
+	// Формули задані для сухої маси
+	kd := 100 / (100 - p.W)
+	c, h, o := f.estimate(fc*kd, p.VM*kd, p.A*kd)
+	raw := Composition{C: c, H: h, O: o, S: p.S * kd, N: p.N * kd, A: p.A * kd}
+
+	// Нормування: C + H + O мають доповнити суху масу до 100 %
+	rest := 100 - raw.A - raw.S - raw.N
+	if c+h+o <= 0 || rest <= 0 {
+		return UltimateEstimate{}, fmt.Errorf("формула %s не дає додатного вмісту C, H, O для цих даних", f.name)
+	}
+	scale := rest / (c + h + o)
+	dry := raw
+	dry.C, dry.H, dry.O = c*scale, h*scale, o*scale
+
+	working := dry.Scale(1 / kd)
+	working.W = p.W
+
+	return UltimateEstimate{
+		Correlation: corr,
+		Estimated:   true,
+		FC:          fc,
+		Raw:         raw,
+		Scale:       scale,
+		Dry:         dry,
+		Working:     working,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package fuel
+
+import (
+	"math"
+	"testing"
+)
+
+func TestEstimateUltimate(t *testing.T) {
+	// W = 10, A = 20, VM = 30, FC за різницею = 40; на суху масу: FC 44.44, VM 33.33, A 22.22
+	est, err := EstimateUltimate(Proximate{W: 10, A: 20, VM: 30}, Parikh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name      string
+		got, want float64
+	}{
+		{"FC", est.FC, 40},
+		{"raw C", est.Raw.C, 43.477778},
+		{"raw H", est.Raw.H, 4.377778},
+		{"raw O", est.Raw.O, 29.377778},
+		{"dry A", est.Dry.A, 22.222222},
+		{"dry sum", est.Dry.Sum(), 100},
+		{"working sum", est.Working.Sum(), 100},
+		{"working W", est.Working.W, 10},
+		{"working A", est.Working.A, 20},
+	} {
+		if math.Abs(c.got-c.want) > 1e-5 {
+			t.Errorf("%s = %.6f, want %.6f", c.name, c.got, c.want)
+		}
+	}
+}
+
+func TestEstimateUltimateFixedCarbonByDifference(t *testing.T) {
+	// Сума в межах допуску, але більша за 100 %: FC не може бути від'ємним
+	est, err := EstimateUltimate(Proximate{W: 10, A: 20, VM: 70.4}, Shen)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if est.FC != 0 {
+		t.Errorf("FC = %v, want 0", est.FC)
+	}
+	if math.Abs(est.Working.Sum()-100) > 1e-9 {
+		t.Errorf("working sum = %v, want 100", est.Working.Sum())
+	}
+}
+
+func TestEstimateUltimateErrors(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		in   Proximate
+		corr ProximateCorrelation
+	}{
+		{"unknown correlation", Proximate{W: 10, A: 20, VM: 30}, "x"},
+		{"sum above 100", Proximate{W: 10, A: 20, VM: 80}, Parikh},
+		{"sum with FC", Proximate{W: 10, A: 20, VM: 30, FC: 50}, Parikh},
+	} {
+		if _, err := EstimateUltimate(c.in, c.corr); err == nil {
+			t.Errorf("%s: expected an error", c.name)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package fuel
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+// SumTolerance - допустиме відхилення суми компонентів від 100 % (в. п.)
+const SumTolerance = 0.5
+
+// FieldError - помилка у значенні конкретного поля.
+// Field збігається з JSON-тегом поля; порожній Field означає помилку всього набору даних.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationErrors - перелік помилок перевірки вхідних даних
+type ValidationErrors []FieldError
+
+func (e ValidationErrors) Error() string {
+	msgs := make([]string, len(e))
+	for i, fe := range e {
+		if fe.Field == "" {
+			msgs[i] = fe.Message
+		} else {
+			msgs[i] = fe.Field + ": " + fe.Message
+		}
+	}
+	return strings.Join(msgs, "; ")
+}
+
+// add - додає помилку для поля
+func (e *ValidationErrors) add(field, format string, args ...any) {
+	*e = append(*e, FieldError{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// percent - перевіряє, що значення є відсотком у межах [0, 100]
+func (e *ValidationErrors) percent(field string, v float64) {
+	if math.IsNaN(v) || v < 0 || v > 100 {
+		e.add(field, "значення має бути в межах від 0 до 100 %%")
+	}
+}
+
+// moistureAndAsh - перевіряє вологу та золу: кожна < 100 %, а разом вони не вичерпують усю масу
+func (e *ValidationErrors) moistureAndAsh(wField string, w float64, aField string, a float64) {
+	e.percent(wField, w)
+	e.percent(aField, a)
+	if w >= 0 && a >= 0 && w+a >= 100 {
+		e.add(aField, "сума вологи та золи має бути менше 100 %%")
+	}
+}
+
+// sum - перевіряє, що сума компонентів дорівнює 100 % з точністю SumTolerance
+func (e *ValidationErrors) sum(total float64) {
+	if math.Abs(total-100) > SumTolerance {
+		e.add("", "сума компонентів %.3f %% відрізняється від 100 %% більш ніж на %.1f %%", total, SumTolerance)
+	}
+}
+
+// Validate - перевіряє склад робочої маси перед Analyze
+func (c Composition) Validate() error {
+	var errs ValidationErrors
+	errs.percent("h", c.H)
+	errs.percent("c", c.C)
+	errs.percent("s", c.S)
+	errs.percent("n", c.N)
+	errs.percent("o", c.O)
+	errs.moistureAndAsh("w", c.W, "a", c.A)
+	errs.sum(c.Sum())
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// ValidateComponents - перевіряє лише елементи складу (без вологи та золи),
+// наприклад, коли склад задано на масі, відмінній від робочої
+func (c Composition) ValidateComponents() error {
+	var errs ValidationErrors
+	errs.percent("h", c.H)
+	errs.percent("c", c.C)
+	errs.percent("s", c.S)
+	errs.percent("n", c.N)
+	errs.percent("o", c.O)
+	if total := c.H + c.C + c.S + c.N + c.O; total > 100+SumTolerance {
+		errs.add("", "сума елементів %.3f %% перевищує 100 %%", total)
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate - перевіряє вхідні дані мазуту перед AnalyzeMazut
+func (in MazutInput) Validate() error {
+	var errs ValidationErrors
+	errs.percent("cg", in.C)
+	errs.percent("hg", in.H)
+	errs.percent("og", in.O)
+	errs.percent("sg", in.S)
+	errs.moistureAndAsh("wg", in.W, "ag", in.A)
+	if in.Qi <= 0 {
+		errs.add("qi", "теплота згоряння має бути додатною")
+	}
+	if in.V < 0 {
+		errs.add("vg", "вміст ванадію не може бути від'ємним")
+	}
+	// Горюча маса мазуту складається з C, H, O, S
+	errs.sum(in.C + in.H + in.O + in.S)
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
+
+// Validate - перевіряє вологу та баласт для таблиці перерахунку
+func (in BasisInput) Validate() error {
+	var errs ValidationErrors
+	if in.Wr < 0 || in.Wr >= 100 {
+		errs.add("wr", "волога має бути в межах [0, 100)")
+	}
+	if in.Wad < 0 || in.Wad >= 100 {
+		errs.add("wad", "волога має бути в межах [0, 100)")
+	}
+	errs.percent("ad", in.Ad)
+	errs.percent("skd", in.Skd)
+	if in.Ad+in.Skd >= 100 {
+		errs.add("skd", "сума золи та колчеданної сірки має бути менше 100 %%")
+	}
+	if len(errs) > 0 {
+		return errs
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package fuel
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"testing"
+)
+
+// fields - поля, на які вказують помилки перевірки ("" - помилка всього набору)
+func fields(t *testing.T, err error) []string {
+	t.Helper()
+	if err == nil {
+		return nil
+	}
+	var errs ValidationErrors
+	if !errors.As(err, &errs) {
+		t.Fatalf("error %v is not ValidationErrors", err)
+	}
+	var names []string
+	for _, fe := range errs {
+		names = append(names, fe.Field)
+	}
+	return names
+}
+
+func TestCompositionValidate(t *testing.T) {
+	for _, c := range []struct {
+		name   string
+		change func(*Composition)
+		want   []string
+	}{
+		{"valid", func(c *Composition) {}, nil},
+		{"sum within tolerance", func(c *Composition) { c.C = 55.6 }, nil},
+		{"sum below tolerance", func(c *Composition) { c.C = 54.6 }, []string{""}},
+		{"sum above tolerance", func(c *Composition) { c.C = 55.8 }, []string{""}},
+		{"moisture and ash just below 100", func(c *Composition) { *c = Composition{C: 0.1, W: 60, A: 39.9} }, nil},
+		{"moisture and ash reach 100", func(c *Composition) { *c = Composition{W: 60, A: 40} }, []string{"a"}},
+		{"negative hydrogen", func(c *Composition) { c.H, c.C = -1, 60 }, []string{"h"}},
+		{"carbon above 100, negative moisture", func(c *Composition) { *c = Composition{C: 101, W: -1} }, []string{"c", "w"}},
+		{"oxygen not a number", func(c *Composition) { c.O = math.NaN() }, []string{"o"}},
+	} {
+		in := gasCoal
+		c.change(&in)
+		if got := fields(t, in.Validate()); !slices.Equal(got, c.want) {
+			t.Errorf("%s: errors on %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestMazutInputValidate(t *testing.T) {
+	valid := MazutInput{C: 85.5, H: 11.2, O: 0.8, S: 2.5, Qi: 40.4, V: 333.3, W: 2, A: 0.15}
+	for _, c := range []struct {
+		name   string
+		change func(*MazutInput)
+		want   []string
+	}{
+		{"valid", func(in *MazutInput) {}, nil},
+		{"no vanadium", func(in *MazutInput) { in.V = 0 }, nil},
+		{"zero heating value", func(in *MazutInput) { in.Qi = 0 }, []string{"qi"}},
+		{"negative vanadium", func(in *MazutInput) { in.V = -1 }, []string{"vg"}},
+		{"moisture and ash reach 100", func(in *MazutInput) { in.W, in.A = 99.9, 0.1 }, []string{"ag"}},
+		{"combustible sum", func(in *MazutInput) { in.S = 3.5 }, []string{""}},
+		{"hydrogen above 100", func(in *MazutInput) { in.H = 101 }, []string{"hg", ""}},
+	} {
+		in := valid
+		c.change(&in)
+		if got := fields(t, in.Validate()); !slices.Equal(got, c.want) {
+			t.Errorf("%s: errors on %q, want %q", c.name, got, c.want)
+		}
+	}
+}
+
+func TestBasisInputValidate(t *testing.T) {
+	valid := BasisInput{Wr: 10, Wad: 2, Ad: 20, Skd: 1}
+	for _, c := range []struct {
+		name   string
+		change func(*BasisInput)
+		want   []string
+	}{
+		{"valid", func(in *BasisInput) {}, nil},
+		{"dry fuel", func(in *BasisInput) { in.Wr, in.Wad = 0, 0 }, nil},
+		{"moisture 100", func(in *BasisInput) { in.Wr = 100 }, []string{"wr"}},
+		{"negative analytical moisture", func(in *BasisInput) { in.Wad = -1 }, []string{"wad"}},
+		{"ash and pyrite reach 100", func(in *BasisInput) { in.Ad, in.Skd = 99, 1 }, []string{"skd"}},
+		{"ash above 100", func(in *BasisInput) { in.Ad = 101 }, []string{"ad", "skd"}},
+	} {
+		in := valid
+		c.change(&in)
+		if got := fields(t, in.Validate()); !slices.Equal(got, c.want) {
+			t.Errorf("%s: errors on %q, want %q", c.name, got, c.want)
+		}
+	}
+}
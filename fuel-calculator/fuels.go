@@ -0,0 +1,144 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/aletiaa/fuel-calculator/fuel"
+)
+
+// Файл, у якому зберігаються палива користувача
+const libraryPath = "fuels.json"
+
+// Глобальна бібліотека палив (вбудовані марки + записи користувача)
+var library *fuel.Library
+
+// homePage - головна сторінка; параметр ?fuel=<id> заповнює форму паливом з бібліотеки
+func homePage(w http.ResponseWriter, r *http.Request) {
+	f := newForm(r)
+	if id := f.text("fuel"); id != "" {
+		e, err := library.Get(id)
+		if err != nil {
+			f.fail("fuel", err.Error())
+		} else {
+			for name, v := range entryValues(e) {
+				f.values[name] = v
+			}
+		}
+	}
+	tmpl.Execute(w, f.page(""))
+}
+
+// entryValues - значення полів форми відповідного завдання для запису бібліотеки
+func entryValues(e fuel.Entry) map[string]string {
+	num := func(v float64) string {
+		return strconv.FormatFloat(math.Round(v*1e4)/1e4, 'f', -1, 64)
+	}
+	switch {
+	case e.Kind == fuel.Solid && e.Working != nil:
+		c := e.Working
+		return map[string]string{
+			"hp": num(c.H), "cp": num(c.C), "sp": num(c.S), "np": num(c.N),
+			"op": num(c.O), "wp": num(c.W), "ap": num(c.A),
+		}
+	case e.Kind == fuel.Mazut && e.Mazut != nil:
+		m := e.Mazut
+		return map[string]string{
+			"cg": num(m.C), "hg": num(m.H), "og": num(m.O), "sg": num(m.S),
+			"qi": num(m.Qi), "vg": num(m.V), "wg": num(m.W), "ag": num(m.A),
+		}
+	}
+	return nil
+}
+
+// saveToLibrary - якщо заповнено поле name, зберігає розраховане паливо як новий запис.
+// Повертає рядок для результату або порожній рядок.
+func saveToLibrary(f *form, name string, e fuel.Entry) string {
+	e.Name = f.text(name)
+	if e.Name == "" {
+		return ""
+	}
+	saved, err := library.Create(e)
+	if err != nil {
+		f.fail(name, err.Error())
+		return ""
+	}
+	return fmt.Sprintf("\nЗбережено у бібліотеці як «%s» (%s)\n", saved.Name, saved.ID)
+}
+
+// --------------------- JSON API бібліотеки ---------------------
+
+// writeLibraryError - відповідь на помилку бібліотеки з відповідним статусом
+func writeLibraryError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, fuel.ErrNotFound):
+		http.Error(w, err.Error(), http.StatusNotFound)
+	case errors.Is(err, fuel.ErrBuiltin):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	default:
+		var errs fuel.ValidationErrors
+		if errors.As(err, &errs) {
+			writeJSONError(w, err)
+			return
+		}
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// apiListFuels - GET /api/fuels
+func apiListFuels(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, library.List())
+}
+
+// apiGetFuel - GET /api/fuels/{id}
+func apiGetFuel(w http.ResponseWriter, r *http.Request) {
+	e, err := library.Get(r.PathValue("id"))
+	if err != nil {
+		writeLibraryError(w, err)
+		return
+	}
+	writeJSON(w, e)
+}
+
+// apiCreateFuel - POST /api/fuels
+func apiCreateFuel(w http.ResponseWriter, r *http.Request) {
+	var e fuel.Entry
+	if !decodeBody(w, r, &e) {
+		return
+	}
+	saved, err := library.Create(e)
+	if err != nil {
+		writeLibraryError(w, err)
+		return
+	}
+	w.Header().Set("Location", "/api/fuels/"+saved.ID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	writeJSON(w, saved)
+}
+
+// apiUpdateFuel - PUT /api/fuels/{id}
+func apiUpdateFuel(w http.ResponseWriter, r *http.Request) {
+	var e fuel.Entry
+	if !decodeBody(w, r, &e) {
+		return
+	}
+	saved, err := library.Update(r.PathValue("id"), e)
+	if err != nil {
+		writeLibraryError(w, err)
+		return
+	}
+	writeJSON(w, saved)
+}
+
+// apiDeleteFuel - DELETE /api/fuels/{id}
+func apiDeleteFuel(w http.ResponseWriter, r *http.Request) {
+	if err := library.Delete(r.PathValue("id")); err != nil {
+		writeLibraryError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -5,6 +5,9 @@ import (
 	"html/template"
 	"net/http"
 	"strconv"
+	"strings"
+
+	"github.com/aletiaa/fuel-calculator/fuel"
 )
 
 // Глобальна змінна для зберігання посилання на завантажений HTML-шаблон
@@ -20,19 +23,45 @@ func main() {
 		return
 	}
 
+	// Відкриваємо бібліотеку палив (файл створюється при першому збереженні)
+	library, err = fuel.OpenLibrary(libraryPath)
+	if err != nil {
+		fmt.Println("Error loading fuel library:", err)
+		return
+	}
+
 	// Налаштовуємо маршрути (URL-шляхи):
-	// 1) "/" - головна сторінка (просто відображає шаблон)
+	// 1) "/" - головна сторінка (?fuel=<id> заповнює форму паливом з бібліотеки)
 	// 2) "/calculate1" - обробник для Завдання 1
 	// 3) "/calculate2" - обробник для Завдання 2
+	// 4) "/convert" - перерахунок складу між масами
+	// 5) "/blend" - суміш палив з бібліотеки
+	// 6) "/proximate" - оцінка елементарного складу за технічним аналізом
+	// 7) "/api/..." - ті самі розрахунки у форматі JSON
+	// 8) "/api/fuels" - бібліотека палив (CRUD)
 
-	http.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
-		// Використовуємо шаблон "index.html" без жодних даних (nil),
-		// щоб просто відкрити головну сторінку
-		tmpl.Execute(w, nil)
-	})
+	http.HandleFunc("/", homePage)
 
 	http.HandleFunc("/calculate1", calculateTask1)
 	http.HandleFunc("/calculate2", calculateTask2)
+	http.HandleFunc("/convert", calculateConversion)
+	http.HandleFunc("/blend", calculateBlend)
+	http.HandleFunc("/proximate", calculateProximate)
+
+	// JSON API для тих самих розрахунків (без HTML)
+	http.HandleFunc("/api/calculate1", apiTask1)
+	http.HandleFunc("/api/calculate2", apiTask2)
+	http.HandleFunc("/api/convert", apiConvert)
+	http.HandleFunc("/api/combustion", apiCombustion)
+	http.HandleFunc("/api/blend", apiBlend)
+	http.HandleFunc("/api/proximate", apiProximate)
+	http.HandleFunc("/api/consumption", apiConsumption)
+
+	http.HandleFunc("GET /api/fuels", apiListFuels)
+	http.HandleFunc("POST /api/fuels", apiCreateFuel)
+	http.HandleFunc("GET /api/fuels/{id}", apiGetFuel)
+	http.HandleFunc("PUT /api/fuels/{id}", apiUpdateFuel)
+	http.HandleFunc("DELETE /api/fuels/{id}", apiDeleteFuel)
 
 	// Запуск веб-сервера на порту 8080
 	fmt.Println("Server running at http://localhost:8080")
@@ -47,47 +76,70 @@ func checkAndToDouble(input string) (float64, error) {
 
 // calculateTask1 - обробник для розрахунків "Завдання 1"
 func calculateTask1(w http.ResponseWriter, r *http.Request) {
-	// Зчитуємо поля з HTML-форми (методом FormValue)
-	hp, _ := checkAndToDouble(r.FormValue("hp"))
-	cp, _ := checkAndToDouble(r.FormValue("cp"))
-	sp, _ := checkAndToDouble(r.FormValue("sp"))
-	np, _ := checkAndToDouble(r.FormValue("np"))
-	op, _ := checkAndToDouble(r.FormValue("op"))
-	wp, _ := checkAndToDouble(r.FormValue("wp"))
-	ap, _ := checkAndToDouble(r.FormValue("ap"))
-
-	// kpc (коефіцієнт для сухої маси) = 100 / (100 - wᵖ)
-	kpc := 100 / (100 - wp)
-
-	// krg (коефіцієнт для горючої маси) = 100 / (100 - wᵖ - aᵖ)
-	krg := 100 / (100 - wp - ap)
-
-	// Розрахунок складу сухої маси
-	hc := hp * kpc
-	cc := cp * kpc
-	sc := sp * kpc
-	nc := np * kpc
-	oc := op * kpc
-	ac := ap * kpc
-
-	// Розрахунок складу горючої маси
-	hg := hp * krg
-	cg := cp * krg
-	sg := sp * krg
-	ng := np * krg
-	og := op * krg
-
-	// Розрахунок нижчої теплоти згорання (робочої маси)
-	qph := (339*cp + 1030*hp - 108.8*(op-sp) - 25*wp) / 1000
-
-	// Для сухої маси
-	qch := (qph + 0.025*wp) * 100 / (100 - wp)
-
-	// Для горючої маси
-	qgh := (qph + 0.025*wp) * 100 / (100 - wp - ap)
-
-	// Формуємо текстовий результат
-	result := fmt.Sprintf(`
+	// Зчитуємо поля з HTML-форми з перевіркою кожного значення
+	f := newForm(r)
+	in := fuel.Composition{
+		H: f.float("hp"),
+		C: f.float("cp"),
+		S: f.float("sp"),
+		N: f.float("np"),
+		O: f.float("op"),
+		W: f.float("wp"),
+		A: f.float("ap"),
+	}
+	corr, err := fuel.ParseCorrelation(f.text("corr"))
+	if err != nil {
+		f.fail("corr", err.Error())
+	}
+	alpha := fuel.DefaultExcessAir
+	if f.text("alpha") != "" {
+		alpha = f.float("alpha")
+	}
+	// Параметри котла необов'язкові: витрата рахується, якщо задано потужність
+	boiler := fuel.ConsumptionInput{
+		Output:     f.optionalFloat("qout"),
+		Efficiency: f.optionalFloat("eta"),
+		Hours:      f.optionalFloat("hours"),
+		Price:      f.optionalFloat("price"),
+	}
+	withBoiler := f.values["qout"] != ""
+	if !withBoiler && (f.values["eta"] != "" || f.values["hours"] != "" || f.values["price"] != "") {
+		// Інакше ККД, години та ціна мовчки ігнорувалися б
+		f.fail("qout", "вкажіть потужність котла, щоб розрахувати витрату палива")
+	}
+	if f.valid() {
+		f.check(in.Validate(), withSuffix("p"))
+	}
+
+	result := ""
+	if f.valid() {
+		res, _ := fuel.AnalyzeWith(in, corr)
+		comb, err := fuel.Combust(in, alpha)
+		if err != nil {
+			f.fail("alpha", err.Error())
+		} else {
+			result = formatTask1(res) + formatCombustion(comb)
+			if withBoiler {
+				boiler.LHV = res.HeatingValue.Working.Lower
+				cons, err := fuel.FuelConsumption(boiler)
+				f.check(err, boilerField)
+				result += formatConsumption(cons, boiler.Price)
+			}
+		}
+	}
+	// Зберігаємо паливо лише тоді, коли вся форма (разом із блоком котла) пройшла перевірку
+	if f.valid() {
+		result += saveToLibrary(f, "save1", fuel.Entry{Kind: fuel.Solid, Working: &in})
+	}
+
+	// Передаємо результат у шаблон разом із введеними значеннями та помилками
+	tmpl.Execute(w, f.page(result))
+}
+
+// formatTask1 - формує текстовий результат "Завдання 1"
+func formatTask1(res fuel.Analysis) string {
+	dry, comb, q := res.Dry, res.Combustible, res.HeatingValue
+	text := fmt.Sprintf(`
 Коеф. (роб. -> суха): %.3f
 Коеф. (роб. -> горюча): %.3f
 
@@ -106,49 +158,138 @@ func calculateTask1(w http.ResponseWriter, r *http.Request) {
   Ng = %.3f %%
   Og = %.3f %%
 
-Теплота (роб. маса): %.3f МДж/кг
-Теплота (суха маса): %.3f МДж/кг
-Теплота (горюча маса): %.3f МДж/кг
+Формула теплоти згоряння: %s
+Теплота (роб. маса): %.3f МДж/кг (вища %.3f)
+Теплота (суха маса): %.3f МДж/кг (вища %.3f)
+Теплота (горюча маса): %.3f МДж/кг (вища %.3f)
 `,
-		kpc, krg,
-		hc, cc, sc, nc, oc, ac,
-		hg, cg, sg, ng, og,
-		qph, qch, qgh,
+		res.Kpc, res.Krg,
+		dry.H, dry.C, dry.S, dry.N, dry.O, dry.A,
+		comb.H, comb.C, comb.S, comb.N, comb.O,
+		q.Correlation.Name(),
+		q.Working.Lower, q.Working.Higher,
+		q.Dry.Lower, q.Dry.Higher,
+		q.Combustible.Lower, q.Combustible.Higher,
 	)
+	if res.Estimated {
+		text = "\nУВАГА: елементарний склад оцінено за технічним аналізом, а не виміряно.\n" + text
+	}
+	return text + formatComparison(res.Comparison)
+}
+
+// formatComparison - таблиця нижчої/вищої теплоти згоряння за всіма формулами
+func formatComparison(rows []fuel.HeatingValues) string {
+	var b strings.Builder
+	b.WriteString("\nПорівняння формул (нижча / вища, МДж/кг):\n")
+	fmt.Fprintf(&b, "  %-15s %-15s %-15s %-15s\n", "Формула", "Робоча", "Суха", "Горюча")
+	for _, hv := range rows {
+		fmt.Fprintf(&b, "  %-15s %6.3f / %-6.3f %6.3f / %-6.3f %6.3f / %-6.3f\n",
+			hv.Correlation.Name(),
+			hv.Working.Lower, hv.Working.Higher,
+			hv.Dry.Lower, hv.Dry.Higher,
+			hv.Combustible.Lower, hv.Combustible.Higher,
+		)
+	}
+	return b.String()
+}
+
+// formatCombustion - об'єми повітря та продуктів згоряння на 1 кг палива
+func formatCombustion(c fuel.Combustion) string {
+	g, pct := c.Gas, c.GasPercent
+	return fmt.Sprintf(`
+Горіння 1 кг палива (α = %.2f):
+  Теоретичне повітря V⁰ = %.3f м³/кг
+  Дійсне повітря αV⁰ = %.3f м³/кг (%.3f кг/кг)
+  Продукти згоряння при α = 1: %.3f м³/кг
+
+Продукти згоряння при α = %.2f:
+  CO2 = %.3f м³/кг (%.2f %%)
+  SO2 = %.4f м³/кг (%.2f %%)
+  H2O = %.3f м³/кг (%.2f %%)
+  N2  = %.3f м³/кг (%.2f %%)
+  O2  = %.3f м³/кг (%.2f %%)
+  Всього = %.3f м³/кг, сухі = %.3f м³/кг
+`,
+		c.Alpha,
+		c.TheoreticalAir,
+		c.ActualAir, c.ActualAirMass,
+		c.Theoretical.Total,
+		c.Alpha,
+		g.CO2, pct.CO2,
+		g.SO2, pct.SO2,
+		g.H2O, pct.H2O,
+		g.N2, pct.N2,
+		g.O2, pct.O2,
+		g.Total, c.DryGas,
+	)
+}
 
-	// Передаємо результат у шаблон. "Result" — ключ, який відповідає {{.Result}} в index.html
-	tmpl.Execute(w, map[string]string{
-		"Result": result,
-	})
+// boilerField - поля параметрів котла у формі "Завдання 1"
+func boilerField(field string) string {
+	return map[string]string{
+		"output":     "qout",
+		"efficiency": "eta",
+		"hours":      "hours",
+		"price":      "price",
+		"lhv":        "corr",
+	}[field]
+}
+
+// formatConsumption - витрата палива котлом і її вартість
+func formatConsumption(c fuel.Consumption, price float64) string {
+	text := fmt.Sprintf(`
+Витрата палива котлом (%.0f год/рік):
+  Натуральне: %.3f т/год, %.1f т/рік
+  Калорійний еквівалент: %.3f
+  Умовне (7000 ккал/кг): %.3f т у.п./год, %.1f т у.п./рік
+`,
+		c.Hours,
+		c.Hourly, c.Annual,
+		c.Equivalent,
+		c.StandardHourly, c.StandardAnnual,
+	)
+	if price > 0 {
+		text += fmt.Sprintf("  Вартість: %.2f грн/год, %.2f грн/рік\n", c.CostHourly, c.CostAnnual)
+	}
+	return text
 }
 
 // --------------------- Завдання 2 ---------------------
 // calculateTask2 - обробник для розрахунків "Завдання 2"
 func calculateTask2(w http.ResponseWriter, r *http.Request) {
 	// Зчитуємо поля з HTML-форми
-	cg, _ := checkAndToDouble(r.FormValue("cg"))
-	hg, _ := checkAndToDouble(r.FormValue("hg"))
-	og, _ := checkAndToDouble(r.FormValue("og"))
-	sg, _ := checkAndToDouble(r.FormValue("sg"))
-	qi, _ := checkAndToDouble(r.FormValue("qi"))
-	vg, _ := checkAndToDouble(r.FormValue("vg"))
-	wg, _ := checkAndToDouble(r.FormValue("wg"))
-	ag, _ := checkAndToDouble(r.FormValue("ag"))
-
-	// Перерахунок складу на робочу масу
-	// cp, hp, op, sp, ap, vp (робоча маса)
-	cp := cg * (100 - wg - ag) / 100
-	hp := hg * (100 - wg - ag) / 100
-	op := og * (100 - wg - ag) / 100
-	sp := sg * (100 - wg - ag) / 100
-	ap := ag * (100 - wg) / 100
-	vp := vg * (100 - wg) / 100
-
-	// Нижча теплота згоряння (з поправкою на робочу масу)
-	qri := qi*(100-wg-ap)/100 - 0.025*wg
-
-	// Формуємо текстовий результат
-	result := fmt.Sprintf(`
+	f := newForm(r)
+	in := fuel.MazutInput{
+		C:  f.float("cg"),
+		H:  f.float("hg"),
+		O:  f.float("og"),
+		S:  f.float("sg"),
+		Qi: f.float("qi"),
+		V:  f.float("vg"),
+		W:  f.float("wg"),
+		A:  f.float("ag"),
+	}
+	if f.valid() {
+		f.check(in.Validate(), sameName)
+	}
+
+	result := ""
+	if f.valid() {
+		result = formatTask2(fuel.AnalyzeMazut(in))
+	}
+	// Зберігаємо паливо лише тоді, коли вся форма пройшла перевірку
+	if f.valid() {
+		result += saveToLibrary(f, "save2", fuel.Entry{Kind: fuel.Mazut, Mazut: &in})
+	}
+
+	// Відображаємо результат у шаблоні
+	tmpl.Execute(w, f.page(result))
+}
+
+// formatTask2 - формує текстовий результат "Завдання 2"
+func formatTask2(res fuel.MazutAnalysis) string {
+	p := res.Working
+	return fmt.Sprintf(`
 Перерахунок елементарного складу мазуту на робочу масу:
   Cp = %.3f %%
   Hp = %.3f %%
@@ -159,11 +300,133 @@ func calculateTask2(w http.ResponseWriter, r *http.Request) {
 
 Нижча теплота згоряння (роб. маса): %.3f МДж/кг
 `,
-		cp, hp, op, sp, ap, vp, qri,
+		p.C, p.H, p.O, p.S, p.A, res.V, res.Qri,
+	) + formatMazutAssessment(res.Assessment)
+}
+
+// formatMazutAssessment - викиди V2O5/SO2, класифікація та попередження для мазуту
+func formatMazutAssessment(a fuel.MazutAssessment) string {
+	text := fmt.Sprintf(`
+Потенційні викиди:
+  V2O5 = %.1f г/т палива
+  SO2 = %.2f кг/т палива (%.1f г/ГДж)
+
+Класифікація:
+  За сіркою: %s
+  За ванадієм: %s
+`,
+		a.V2O5,
+		a.SO2, a.SO2PerGJ,
+		a.SulfurGrade,
+		a.VanadiumGrade,
 	)
+	if len(a.Warnings) > 0 {
+		text += "\nПопередження:\n  ! " + strings.Join(a.Warnings, "\n  ! ") + "\n"
+	}
+	return text
+}
 
-	// Відображаємо результат у шаблоні
-	tmpl.Execute(w, map[string]string{
-		"Result": result,
-	})
+// --------------------- Перерахунок між масами ---------------------
+// calculateConversion - обробник перерахунку складу з однієї маси на іншу
+func calculateConversion(w http.ResponseWriter, r *http.Request) {
+	f := newForm(r)
+	basis := fuel.BasisInput{
+		Wr:  f.float("wr"),
+		Wad: f.float("wad"),
+		Ad:  f.float("ad"),
+		Skd: f.optionalFloat("skd"),
+	}
+	from, to := f.text("from"), f.text("to")
+	c := fuel.Composition{
+		H: f.float("h"),
+		C: f.float("c"),
+		S: f.float("s"),
+		N: f.float("n"),
+		O: f.float("o"),
+	}
+	if f.valid() {
+		f.check(c.ValidateComponents(), sameName)
+	}
+
+	result := ""
+	if f.valid() {
+		res, err := convertComposition(basis, from, to, c)
+		f.check(err, sameName)
+		if f.valid() {
+			result = formatConversion(res)
+		}
+	}
+
+	tmpl.Execute(w, f.page(result))
+}
+
+// conversionResult - таблиця перерахунку разом із перерахованим складом
+type conversionResult struct {
+	From   fuel.Basis           `json:"from"`
+	To     fuel.Basis           `json:"to"`
+	Table  fuel.ConversionTable `json:"table"`
+	Result fuel.Composition     `json:"result"`
+}
+
+// convertComposition - будує таблицю та перераховує склад з маси from на масу to
+func convertComposition(in fuel.BasisInput, from, to string, c fuel.Composition) (conversionResult, error) {
+	fromBasis, err := fuel.ParseBasis(from)
+	if err != nil {
+		return conversionResult{}, fuel.ValidationErrors{{Field: "from", Message: err.Error()}}
+	}
+	toBasis, err := fuel.ParseBasis(to)
+	if err != nil {
+		return conversionResult{}, fuel.ValidationErrors{{Field: "to", Message: err.Error()}}
+	}
+	table, err := fuel.NewConversionTable(in)
+	if err != nil {
+		return conversionResult{}, err
+	}
+	return conversionResult{
+		From:   fromBasis,
+		To:     toBasis,
+		Table:  table,
+		Result: table.Convert(c, fromBasis, toBasis),
+	}, nil
+}
+
+// formatConversion - формує текстовий результат перерахунку з таблицею коефіцієнтів
+func formatConversion(res conversionResult) string {
+	var b strings.Builder
+
+	b.WriteString("\nБаласт на кожній масі:\n")
+	for _, basis := range fuel.Bases {
+		bl := res.Table.Ballast[basis]
+		fmt.Fprintf(&b, "  %-11s W = %.3f %%, A = %.3f %%, Sk = %.3f %%\n", basis.Name(), bl.W, bl.A, bl.Sk)
+	}
+
+	b.WriteString("\nКоефіцієнти перерахунку (рядок -> стовпець):\n")
+	fmt.Fprintf(&b, "%12s", "")
+	for _, to := range fuel.Bases {
+		fmt.Fprintf(&b, "%8s", to)
+	}
+	b.WriteString("\n")
+	for _, from := range fuel.Bases {
+		fmt.Fprintf(&b, "  %-10s", from.Name())
+		for _, to := range fuel.Bases {
+			fmt.Fprintf(&b, "%8.4f", res.Table.Factors[from][to])
+		}
+		b.WriteString("\n")
+	}
+
+	c := res.Result
+	fmt.Fprintf(&b, `
+Склад (%s -> %s), коеф. %.4f:
+  H = %.3f %%
+  C = %.3f %%
+  S = %.3f %%
+  N = %.3f %%
+  O = %.3f %%
+  W = %.3f %%
+  A = %.3f %%
+`,
+		res.From.Name(), res.To.Name(), res.Table.Factors[res.From][res.To],
+		c.H, c.C, c.S, c.N, c.O, c.W, c.A,
+	)
+	return b.String()
 }
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"html/template"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aletiaa/fuel-calculator/fuel"
+)
+
+// setupHandlers - шаблон і порожня бібліотека у тимчасовому каталозі
+func setupHandlers(t *testing.T) {
+	t.Helper()
+	var err error
+	if tmpl, err = template.ParseFiles("index.html"); err != nil {
+		t.Fatal(err)
+	}
+	if library, err = fuel.OpenLibrary(filepath.Join(t.TempDir(), "fuels.json")); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func post(handler http.HandlerFunc, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	rec := httptest.NewRecorder()
+	handler(rec, req)
+	return rec
+}
+
+func userEntries() int {
+	n := 0
+	for _, e := range library.List() {
+		if !e.Builtin {
+			n++
+		}
+	}
+	return n
+}
+
+func TestSaveOnlyWhenFormIsValid(t *testing.T) {
+	setupHandlers(t)
+	task1 := url.Values{
+		"hp": {"3.8"}, "cp": {"55.2"}, "sp": {"3.2"}, "np": {"1.0"}, "op": {"5.8"}, "wp": {"10"}, "ap": {"21.0"},
+		"save1": {"Вугілля Г"},
+	}
+
+	// Невірний ККД котла: паливо не зберігається
+	invalid := url.Values{"qout": {"100"}, "eta": {"150"}}
+	for k, v := range task1 {
+		invalid[k] = v
+	}
+	post(calculateTask1, invalid)
+	if n := userEntries(); n != 0 {
+		t.Fatalf("saved %d entries with an invalid boiler block", n)
+	}
+
+	post(calculateTask1, task1)
+	if n := userEntries(); n != 1 {
+		t.Fatalf("saved %d entries from a valid form, want 1", n)
+	}
+
+	task2 := url.Values{
+		"cg": {"85.5"}, "hg": {"11.2"}, "og": {"0.8"}, "sg": {"2.5"}, "qi": {"40.40"}, "vg": {"333.3"}, "wg": {"2"}, "ag": {"0.15"},
+		"save2": {"Мазут"},
+	}
+	bad := url.Values{}
+	for k, v := range task2 {
+		bad[k] = v
+	}
+	bad.Set("wg", "-1")
+	post(calculateTask2, bad)
+	if n := userEntries(); n != 1 {
+		t.Fatalf("saved an entry from an invalid mazut form")
+	}
+	post(calculateTask2, task2)
+	if n := userEntries(); n != 2 {
+		t.Fatalf("%d entries after a valid mazut form, want 2", n)
+	}
+}
+
+func TestBoilerBlockNeedsOutput(t *testing.T) {
+	setupHandlers(t)
+	for _, c := range []struct {
+		name    string
+		boiler  url.Values
+		wantErr string
+	}{
+		{"no boiler", url.Values{}, ""},
+		{"full boiler", url.Values{"qout": {"100"}, "eta": {"90"}, "price": {"4000"}}, ""},
+		{"efficiency without output", url.Values{"eta": {"90"}}, "qout: вкажіть потужність"},
+		{"price without output", url.Values{"price": {"4000"}}, "qout: вкажіть потужність"},
+		{"zero output", url.Values{"qout": {"0"}, "eta": {"90"}}, "qout: потужність має бути додатною"},
+	} {
+		form := url.Values{"hp": {"3.8"}, "cp": {"55.2"}, "sp": {"3.2"}, "np": {"1.0"}, "op": {"5.8"}, "wp": {"10"}, "ap": {"21.0"}}
+		for k, v := range c.boiler {
+			form[k] = v
+		}
+		body := post(calculateTask1, form).Body.String()
+		failed := strings.Contains(body, "Помилки у введених даних")
+		switch {
+		case c.wantErr == "" && failed:
+			t.Errorf("%s: unexpected validation error", c.name)
+		case c.wantErr != "" && !strings.Contains(body, c.wantErr):
+			t.Errorf("%s: no error %q", c.name, c.wantErr)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/aletiaa/fuel-calculator/fuel"
+)
+
+// --------------------- Оцінка за технічним аналізом ---------------------
+// calculateProximate - оцінює елементарний склад за технічним аналізом
+// і проганяє його через розрахунок "Завдання 1"
+func calculateProximate(w http.ResponseWriter, r *http.Request) {
+	f := newForm(r)
+	in := fuel.Proximate{
+		W:  f.float("xw"),
+		A:  f.float("xa"),
+		VM: f.float("xvm"),
+		FC: f.optionalFloat("xfc"),
+		S:  f.optionalFloat("xs"),
+		N:  f.optionalFloat("xn"),
+	}
+	corr, err := fuel.ParseProximateCorrelation(f.text("xcorr"))
+	if err != nil {
+		f.fail("xcorr", err.Error())
+	}
+	heating, err := fuel.ParseCorrelation(f.text("xhcorr"))
+	if err != nil {
+		f.fail("xhcorr", err.Error())
+	}
+
+	result := ""
+	if f.valid() {
+		est, res, err := estimateAndAnalyze(in, corr, heating)
+		f.check(err, withPrefix("x"))
+		if f.valid() {
+			result = formatEstimate(est) + formatTask1(res)
+
+			// Заповнюємо форму "Завдання 1" оціненим складом для подальших розрахунків
+			for name, v := range entryValues(fuel.Entry{Kind: fuel.Solid, Working: &est.Working}) {
+				f.values[name] = v
+			}
+		}
+	}
+
+	tmpl.Execute(w, f.page(result))
+}
+
+// estimateAndAnalyze - оцінка складу та аналіз робочої маси з позначкою "оцінено";
+// теплота згоряння рахується за формулою heating
+func estimateAndAnalyze(in fuel.Proximate, corr fuel.ProximateCorrelation, heating fuel.Correlation) (fuel.UltimateEstimate, fuel.Analysis, error) {
+	est, err := fuel.EstimateUltimate(in, corr)
+	if err != nil {
+		return fuel.UltimateEstimate{}, fuel.Analysis{}, err
+	}
+	res, err := fuel.AnalyzeWith(est.Working, heating)
+	if err != nil {
+		return fuel.UltimateEstimate{}, fuel.Analysis{}, err
+	}
+	res.Estimated = true
+	return est, res, nil
+}
+
+// formatEstimate - формує текстовий результат оцінки складу
+func formatEstimate(est fuel.UltimateEstimate) string {
+	p := est.Working
+	return fmt.Sprintf(`
+Оцінка складу за формулою: %s (FC = %.3f %%, нормування C, H, O x%.4f)
+
+Оцінена робоча маса:
+  Hp = %.3f %%
+  Cp = %.3f %%
+  Sp = %.3f %%
+  Np = %.3f %%
+  Op = %.3f %%
+  Wp = %.3f %%
+  Ap = %.3f %%
+`,
+		est.Correlation.Name(), est.FC, est.Scale,
+		p.H, p.C, p.S, p.N, p.O, p.W, p.A,
+	)
+}
+
+// proximateResult - відповідь /api/proximate
+type proximateResult struct {
+	Estimate fuel.UltimateEstimate `json:"estimate"`
+	Analysis fuel.Analysis         `json:"analysis"`
+}
+
+// apiProximate - JSON-версія оцінки складу; формулу оцінки можна обрати параметром ?correlation=,
+// формулу теплоти згоряння - параметром ?heating=
+func apiProximate(w http.ResponseWriter, r *http.Request) {
+	var in fuel.Proximate
+	if !decodeJSON(w, r, &in) {
+		return
+	}
+	corr, err := fuel.ParseProximateCorrelation(r.URL.Query().Get("correlation"))
+	if err != nil {
+		writeJSONError(w, fuel.ValidationErrors{{Field: "correlation", Message: err.Error()}})
+		return
+	}
+	heating, err := fuel.ParseCorrelation(r.URL.Query().Get("heating"))
+	if err != nil {
+		writeJSONError(w, fuel.ValidationErrors{{Field: "heating", Message: err.Error()}})
+		return
+	}
+	est, res, err := estimateAndAnalyze(in, corr, heating)
+	if err != nil {
+		writeJSONError(w, err)
+		return
+	}
+	writeJSON(w, proximateResult{Estimate: est, Analysis: res})
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/aletiaa/fuel-calculator/fuel"
+)
+
+func TestEstimateAndAnalyzeHeatingCorrelation(t *testing.T) {
+	in := fuel.Proximate{W: 10, A: 20, VM: 30}
+	for _, corr := range fuel.Correlations {
+		est, res, err := estimateAndAnalyze(in, fuel.Parikh, corr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, _ := fuel.HeatingValuesFor(est.Working, corr)
+		if res.HeatingValue != want {
+			t.Errorf("%s: heating value %+v, want %+v", corr, res.HeatingValue, want)
+		}
+		if !res.Estimated {
+			t.Errorf("%s: Estimated = false", corr)
+		}
+	}
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// Battery sizes evaluated in the sizing table, as multiples of the entered capacity
+const sizingSteps, sizingRange = 20, 4.0
+
+// Default and maximum number of simulated days (every day is replayed for each size)
+const batteryDays, maxBatteryDays = 365, 3650
+
+// Battery that absorbs deviations from the forecast before they are settled
+type Battery struct {
+	Capacity   float64 // MWh
+	Power      float64 // MW
+	Efficiency float64 // Round-trip efficiency, fraction
+	Cost       float64 // Capital cost per MWh of capacity
+	Lifetime   int     // Years
+	Rate       float64 // Discount rate, fraction per year
+}
+
+// Parse the battery fields; ok is false when no capacity was entered
+func parseBattery(r *http.Request) (Battery, bool, error) {
+	if r.FormValue("batteryCapacity") == "" {
+		return Battery{}, false, nil
+	}
+	b := Battery{Efficiency: 0.9, Lifetime: 15, Rate: 0.1}
+	number := func(name string, v *float64, scale float64) error {
+		s := r.FormValue(name)
+		if s == "" {
+			return nil
+		}
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil || x < 0 {
+			return fmt.Errorf("Invalid input for %s", name)
+		}
+		*v = x * scale
+		return nil
+	}
+	if err := number("batteryCapacity", &b.Capacity, 1); err != nil || b.Capacity == 0 {
+		return b, false, fmt.Errorf("Invalid input for batteryCapacity")
+	}
+	b.Power = b.Capacity // 1C by default
+	if err := number("batteryPower", &b.Power, 1); err != nil || b.Power == 0 {
+		return b, false, fmt.Errorf("Invalid input for batteryPower")
+	}
+	if err := number("batteryEfficiency", &b.Efficiency, 0.01); err != nil || b.Efficiency == 0 || b.Efficiency > 1 {
+		return b, false, fmt.Errorf("Invalid input for batteryEfficiency")
+	}
+	if err := number("batteryCost", &b.Cost, 1); err != nil {
+		return b, false, err
+	}
+	if err := number("discountRate", &b.Rate, 0.01); err != nil {
+		return b, false, err
+	}
+	if s := r.FormValue("batteryLifetime"); s != "" {
+		years, err := strconv.Atoi(s)
+		if err != nil || years < 1 || years > 100 {
+			return b, false, fmt.Errorf("Invalid input for batteryLifetime")
+		}
+		b.Lifetime = years
+	}
+	return b, true, nil
+}
+
+// Daily capital cost of the battery: annuity of the capex over the lifetime
+func (b Battery) DailyCost() float64 {
+	capex := b.Capacity * b.Cost
+	n := float64(b.Lifetime)
+	if b.Rate == 0 {
+		return capex / n / daysPerYear
+	}
+	growth := math.Pow(1+b.Rate, n)
+	return capex * b.Rate * growth / (growth - 1) / daysPerYear
+}
+
+// Battery of a different capacity with the same C-rate and costs
+func (b Battery) Resize(capacity float64) Battery {
+	b.Power *= capacity / b.Capacity
+	b.Capacity = capacity
+	return b
+}
+
+// Settle the sampled days of the current forecast with the battery in front of the meter.
+// The battery only acts when it can bring the interval fully into the band; inside the band
+// it uses the headroom to drift back to half charge. Returns the average day and the MWh discharged per day.
+func (b Battery) settle(p Profile, tolerance Tolerance, pricing Pricing, z [][]float64) (Settlement, float64) {
+	hours := p.IntervalHours()
+	eff := math.Sqrt(b.Efficiency) // One-way efficiency
+	target := b.Capacity / 2
+	var total Settlement
+	discharged := 0.0
+
+	for _, day := range z {
+		soc := target
+		for i, forecast := range p.Forecast {
+			lower, upper := tolerance.Band(forecast, p.StdDevAfter[i])
+			actual := forecast + p.Bias[i] + p.StdDevBefore[i]*day[i]
+
+			// Power the battery can take or give during this interval, MW
+			charge := math.Min(b.Power, (b.Capacity-soc)/(hours*eff))
+			discharge := math.Min(b.Power, soc*eff/hours)
+
+			switch {
+			case actual > upper && actual-upper <= charge:
+				soc += (actual - upper) * hours * eff
+				actual = upper
+			case actual < lower && lower-actual <= discharge:
+				soc -= (lower - actual) * hours / eff
+				discharged += (lower - actual) * hours
+				actual = lower
+			case actual >= lower && actual <= upper && soc < target:
+				c := math.Min(math.Min(charge, actual-lower), (target-soc)/(hours*eff))
+				soc += c * hours * eff
+			case actual >= lower && actual <= upper && soc > target:
+				d := math.Min(math.Min(discharge, upper-actual), (soc-target)*eff/hours)
+				soc -= d * hours / eff
+				discharged += d * hours
+			}
+
+			energy := forecast * hours
+			switch {
+			case actual > upper:
+				total.Surplus += energy
+			case actual < lower:
+				total.Deficit += energy
+			default:
+				total.InBand += energy
+			}
+		}
+	}
+
+	days := float64(len(z))
+	total.InBand /= days
+	total.Surplus /= days
+	total.Deficit /= days
+	total.Price(pricing)
+	return total, discharged / days
+}
+
+// Battery size in the sizing table
+type SizingPoint struct {
+	Capacity, Power float64
+	ExtraRevenue    float64 // Daily profit gain over no battery
+	Cost            float64 // Daily capital cost
+	Net             float64
+}
+
+// Battery result for the current forecast
+type BatteryResult struct {
+	Battery
+	Days       int
+	Seed       int64
+	Without    Settlement
+	With       Settlement
+	Discharged float64 // MWh per day
+	Eliminated float64 // Share of W2 removed by the battery
+	DailyCost  float64
+	Sizing     []SizingPoint
+	Optimal    SizingPoint // Size with the highest net benefit
+}
+
+// Simulate the current forecast with and without the battery and size it.
+// The same standardised errors are used for every size, so the sizing curve is smooth.
+func evaluateBattery(p Profile, tolerance Tolerance, dist ErrorDistribution, pricing Pricing, b Battery, sim Simulation) BatteryResult {
+	rng := rand.New(rand.NewSource(sim.Seed))
+	table := newQuantileTable(dist)
+	z := make([][]float64, sim.Days)
+	for d := range z {
+		z[d] = make([]float64, len(p.Forecast))
+		for i := range z[d] {
+			z[d][i] = table.At(rng.Float64())
+		}
+	}
+
+	res := BatteryResult{Battery: b, Days: sim.Days, Seed: sim.Seed, DailyCost: b.DailyCost()}
+	res.Without, _ = b.Resize(0).settle(p, tolerance, pricing, z)
+	res.With, res.Discharged = b.settle(p, tolerance, pricing, z)
+	if res.Without.Imbalance > 0 {
+		res.Eliminated = 1 - res.With.Imbalance/res.Without.Imbalance
+	}
+
+	for k := 0; k <= sizingSteps; k++ {
+		size := b.Resize(b.Capacity * sizingRange * float64(k) / sizingSteps)
+		s, _ := size.settle(p, tolerance, pricing, z)
+		point := SizingPoint{
+			Capacity:     size.Capacity,
+			Power:        size.Power,
+			ExtraRevenue: s.FinalProfit - res.Without.FinalProfit,
+			Cost:         size.DailyCost(),
+		}
+		point.Net = point.ExtraRevenue - point.Cost
+		res.Sizing = append(res.Sizing, point)
+		if k == 0 || point.Net > res.Optimal.Net {
+			res.Optimal = point
+		}
+	}
+	return res
+}
+
+// Standardised quantile (mean 0, σ 1) of the error distribution by bisection
+func quantile(dist ErrorDistribution, u float64) float64 {
+	lo, hi := -10.0, 10.0
+	for dist.CDF(lo, 0, 1) > u && lo > -1e6 {
+		lo *= 2
+	}
+	for dist.CDF(hi, 0, 1) < u && hi < 1e6 {
+		hi *= 2
+	}
+	for i := 0; i < 60; i++ {
+		mid := (lo + hi) / 2
+		if dist.CDF(mid, 0, 1) < u {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// Grid of the quantile table: normal scores from -quantileRange to +quantileRange
+const quantileSteps, quantileRange = 1600, 8.0
+
+// Inverse CDF of a standardised error distribution, tabulated once by bisection on a grid of
+// probabilities that is dense in the tails; lookups interpolate linearly between the grid points
+type quantileTable struct {
+	dist ErrorDistribution
+	u, x []float64
+}
+
+func newQuantileTable(dist ErrorDistribution) quantileTable {
+	q := quantileTable{dist: dist, u: make([]float64, quantileSteps+1), x: make([]float64, quantileSteps+1)}
+	for k := range q.u {
+		q.u[k] = normalCDF(quantileRange*(2*float64(k)/quantileSteps-1), 0, 1)
+		q.x[k] = quantile(dist, q.u[k])
+	}
+	return q
+}
+
+// Standardised quantile at probability u; beyond the grid it is solved directly
+func (q quantileTable) At(u float64) float64 {
+	last := len(q.u) - 1
+	if u <= q.u[0] || u >= q.u[last] {
+		return quantile(q.dist, u)
+	}
+	i := sort.SearchFloat64s(q.u, u) // q.u[i-1] < u <= q.u[i]
+	frac := (u - q.u[i-1]) / (q.u[i] - q.u[i-1])
+	return q.x[i-1] + frac*(q.x[i]-q.x[i-1])
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestQuantileTable(t *testing.T) {
+	samples := []float64{-3, -1.2, -0.8, -0.5, -0.1, 0, 0.2, 0.4, 0.9, 1.5, 2.8, -2}
+	empirical, err := NewEmpirical(samples)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dists := []ErrorDistribution{Normal{}, Laplace{}, StudentT{4}, SkewNormal{0.5}, empirical}
+	probabilities := []float64{1e-9, 1e-4, 0.0228, 0.3, 0.5, 0.841345, 0.999, 1 - 1e-9}
+	for _, dist := range dists {
+		table := newQuantileTable(dist)
+		for _, u := range probabilities {
+			want := quantile(dist, u)
+			approx(t, dist.String(), table.At(u), want, 1e-3*math.Max(1, math.Abs(want)))
+		}
+	}
+
+	normal := newQuantileTable(Normal{})
+	approx(t, "z(0.841345)", normal.At(0.841345), 1, 1e-5)
+	approx(t, "z(0.975002)", normal.At(0.975002), 1.96, 1e-5)
+	approx(t, "z(0.5)", normal.At(0.5), 0, 1e-9)
+}
+
+func TestBatteryDailyCost(t *testing.T) {
+	tests := []struct {
+		b    Battery
+		want float64
+	}{
+		{Battery{Capacity: 1000, Cost: 100, Lifetime: 15, Rate: 0.1}, 36.020213},
+		{Battery{Capacity: 1000, Cost: 100, Lifetime: 10, Rate: 0}, 100000.0 / 10 / 365},
+	}
+	for _, tt := range tests {
+		approx(t, "daily cost", tt.b.DailyCost(), tt.want, 1e-6)
+	}
+}
+
+func TestEvaluateBattery(t *testing.T) {
+	// Flat 5 MW, σ1 = 1, ±1 MW band: without a battery 68.27 % of the energy is in the band
+	p := Profile{Forecast: repeat(5, 24), StdDevBefore: repeat(1, 24), StdDevAfter: repeat(1, 24), Bias: repeat(0, 24)}
+	pricing := Pricing{EnergyCost: 7, SurplusPrice: 7, DeficitPrice: 7, SurplusMultiplier: 1, DeficitMultiplier: 1}
+	tolerance := Tolerance{Mode: ToleranceAbsolute, Lower: 1, Upper: 1}
+	b := Battery{Capacity: 2, Power: 2, Efficiency: 0.9, Cost: 1000, Lifetime: 15, Rate: 0.1}
+
+	res := evaluateBattery(p, tolerance, Normal{}, pricing, b, Simulation{Days: 2000, Seed: 1})
+	approx(t, "share without battery", res.Without.Share, 0.682689, 0.01)
+	if res.With.Share <= res.Without.Share || res.Eliminated <= 0 || res.Discharged <= 0 {
+		t.Errorf("battery does not reduce imbalances: share %.4f → %.4f, eliminated %.4f", res.Without.Share, res.With.Share, res.Eliminated)
+	}
+	if res.Sizing[0].Capacity != 0 || res.Sizing[0].ExtraRevenue != 0 {
+		t.Errorf("first sizing point %+v, want no battery", res.Sizing[0])
+	}
+	if len(res.Sizing) != sizingSteps+1 {
+		t.Errorf("%d sizing points, want %d", len(res.Sizing), sizingSteps+1)
+	}
+
+	// The largest request (ten years of 15-minute intervals, heavy-tailed errors) stays fast
+	q := Profile{Forecast: repeat(5, 96), StdDevBefore: repeat(1, 96), StdDevAfter: repeat(1, 96), Bias: repeat(0, 96)}
+	start := time.Now()
+	evaluateBattery(q, tolerance, StudentT{4}, pricing, b, Simulation{Days: maxBatteryDays, Seed: 1})
+	if d := time.Since(start); d > 20*time.Second {
+		t.Errorf("battery evaluation took %v", d)
+	}
+}
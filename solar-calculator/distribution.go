@@ -0,0 +1,252 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+)
+
+// Error distribution models
+const (
+	DistNormal     = "normal"
+	DistLaplace    = "laplace"
+	DistStudentT   = "studentt"
+	DistSkewNormal = "skewnormal"
+	DistEmpirical  = "empirical"
+)
+
+// Shape of the forecast error. Every model is parameterised by the mean and the
+// standard deviation of the actual power, so σ1/σ2 keep their meaning for all shapes.
+type ErrorDistribution interface {
+	CDF(p, mean, stdDev float64) float64
+	String() string
+}
+
+// Normal (Gaussian) errors - the original model
+type Normal struct{}
+
+func (Normal) CDF(p, mean, stdDev float64) float64 { return normalCDF(p, mean, stdDev) }
+func (Normal) String() string                      { return "нормальний" }
+
+// Laplace (double exponential) errors: sharper peak and heavier tails than the normal
+type Laplace struct{}
+
+func (Laplace) CDF(p, mean, stdDev float64) float64 {
+	b := stdDev / math.Sqrt2
+	if p < mean {
+		return 0.5 * math.Exp((p-mean)/b)
+	}
+	return 1 - 0.5*math.Exp(-(p-mean)/b)
+}
+
+func (Laplace) String() string { return "Лапласа" }
+
+// Student-t errors with Nu > 2 degrees of freedom, scaled to the given standard deviation
+type StudentT struct {
+	Nu float64
+}
+
+func (d StudentT) CDF(p, mean, stdDev float64) float64 {
+	t := (p - mean) / (stdDev * math.Sqrt((d.Nu-2)/d.Nu))
+	tail := 0.5 * regularizedBeta(d.Nu/(d.Nu+t*t), d.Nu/2, 0.5)
+	if t > 0 {
+		return 1 - tail
+	}
+	return tail
+}
+
+func (d StudentT) String() string { return fmt.Sprintf("Стьюдента (ν = %.3g)", d.Nu) }
+
+// Skew-normal errors with the given skewness (|Skew| < 0.995)
+type SkewNormal struct {
+	Skew float64
+}
+
+// Shape parameter δ = α/√(1+α²) reproducing the skewness
+func (d SkewNormal) delta() float64 {
+	g := math.Pow(math.Abs(d.Skew), 2.0/3)
+	delta := math.Sqrt(math.Pi / 2 * g / (g + math.Pow((4-math.Pi)/2, 2.0/3)))
+	return math.Copysign(delta, d.Skew)
+}
+
+func (d SkewNormal) CDF(p, mean, stdDev float64) float64 {
+	delta := d.delta()
+	alpha := delta / math.Sqrt(1-delta*delta)
+	omega := stdDev / math.Sqrt(1-2*delta*delta/math.Pi)
+	xi := mean - omega*delta*math.Sqrt(2/math.Pi)
+	z := (p - xi) / omega
+	return normalCDF(z, 0, 1) - 2*owenT(z, alpha)
+}
+
+func (d SkewNormal) String() string {
+	return fmt.Sprintf("скошений нормальний (асиметрія %.3g)", d.Skew)
+}
+
+// Empirical histogram of historical errors. The errors are standardised, so the
+// shape is shifted and scaled to the mean and σ of every interval.
+type Empirical struct {
+	Edges  []float64 // Bin edges of the standardised errors
+	Cumul  []float64 // Cumulative share at every edge
+	Sample int
+}
+
+// Build the histogram with Sturges' number of bins
+func NewEmpirical(errs []float64) (Empirical, error) {
+	n := float64(len(errs))
+	if len(errs) < 10 {
+		return Empirical{}, fmt.Errorf("at least 10 error samples are required")
+	}
+	mean, sd := 0.0, 0.0
+	for _, e := range errs {
+		mean += e
+	}
+	mean /= n
+	for _, e := range errs {
+		sd += (e - mean) * (e - mean)
+	}
+	sd = math.Sqrt(sd / (n - 1))
+	if sd == 0 {
+		return Empirical{}, fmt.Errorf("error samples must not all be equal")
+	}
+
+	z := make([]float64, len(errs))
+	for i, e := range errs {
+		z[i] = (e - mean) / sd
+	}
+	sort.Float64s(z)
+	bins := int(math.Ceil(math.Log2(n))) + 1
+	lo, hi := z[0], z[len(z)-1]
+	width := (hi - lo) / float64(bins)
+
+	d := Empirical{Edges: make([]float64, bins+1), Cumul: make([]float64, bins+1), Sample: len(errs)}
+	j := 0
+	for i := range d.Edges {
+		d.Edges[i] = lo + float64(i)*width
+		for j < len(z) && (z[j] <= d.Edges[i] || i == bins) {
+			j++
+		}
+		d.Cumul[i] = float64(j) / n
+	}
+	d.Cumul[0] = 0
+	return d, nil
+}
+
+// Piecewise-linear CDF of the histogram
+func (d Empirical) CDF(p, mean, stdDev float64) float64 {
+	z := (p - mean) / stdDev
+	last := len(d.Edges) - 1
+	switch {
+	case z <= d.Edges[0]:
+		return 0
+	case z >= d.Edges[last]:
+		return 1
+	}
+	i := sort.SearchFloat64s(d.Edges, z) // d.Edges[i-1] < z <= d.Edges[i]
+	frac := (z - d.Edges[i-1]) / (d.Edges[i] - d.Edges[i-1])
+	return d.Cumul[i-1] + frac*(d.Cumul[i]-d.Cumul[i-1])
+}
+
+func (d Empirical) String() string {
+	return fmt.Sprintf("емпіричний (%d значень, %d інтервалів)", d.Sample, len(d.Edges)-1)
+}
+
+// Regularized incomplete beta function I_x(a, b), continued fraction (Numerical Recipes)
+func regularizedBeta(x, a, b float64) float64 {
+	if x <= 0 {
+		return 0
+	}
+	if x >= 1 {
+		return 1
+	}
+	la, _ := math.Lgamma(a)
+	lb, _ := math.Lgamma(b)
+	lab, _ := math.Lgamma(a + b)
+	front := math.Exp(lab - la - lb + a*math.Log(x) + b*math.Log(1-x))
+	if x > (a+1)/(a+b+2) {
+		return 1 - front*betaFraction(1-x, b, a)/b
+	}
+	return front * betaFraction(x, a, b) / a
+}
+
+// Continued fraction for the incomplete beta function (modified Lentz method)
+func betaFraction(x, a, b float64) float64 {
+	const tiny = 1e-300
+	nonZero := func(v float64) float64 {
+		if math.Abs(v) < tiny {
+			return tiny
+		}
+		return v
+	}
+	c, d := 1.0, 1/nonZero(1-(a+b)*x/(a+1))
+	f := d
+	for m := 1.0; m <= 200; m++ {
+		// Even step
+		num := m * (b - m) * x / ((a + 2*m - 1) * (a + 2*m))
+		d = 1 / nonZero(1+num*d)
+		c = nonZero(1 + num/c)
+		f *= d * c
+
+		// Odd step
+		num = -(a + m) * (a + b + m) * x / ((a + 2*m) * (a + 2*m + 1))
+		d = 1 / nonZero(1+num*d)
+		c = nonZero(1 + num/c)
+		f *= d * c
+		if math.Abs(d*c-1) < 1e-12 {
+			break
+		}
+	}
+	return f
+}
+
+// Owen's T function T(h, a), Gauss–Legendre quadrature over [0, a]
+func owenT(h, a float64) float64 {
+	// 20-point Gauss–Legendre nodes and weights on [-1, 1] (positive half)
+	nodes := [...]float64{0.0765265211334973, 0.2277858511416451, 0.3737060887154195, 0.5108670019508271, 0.6360536807265150,
+		0.7463319064601508, 0.8391169718222188, 0.9122344282513259, 0.9639719272779138, 0.9931285991850949}
+	weights := [...]float64{0.1527533871307258, 0.1491729864726037, 0.1420961093183820, 0.1316886384491766, 0.1181945319615184,
+		0.1019301198172404, 0.0832767415767048, 0.0626720483341091, 0.0406014298003869, 0.0176140071391521}
+	f := func(x float64) float64 {
+		return math.Exp(-h*h*(1+x*x)/2) / (1 + x*x)
+	}
+	half := a / 2
+	sum := 0.0
+	for i, x := range nodes {
+		sum += weights[i] * (f(half*(1-x)) + f(half*(1+x)))
+	}
+	return sum * half / (2 * math.Pi)
+}
+
+// Parse the error distribution fields of the form
+func parseDistribution(r *http.Request) (ErrorDistribution, error) {
+	switch r.FormValue("distribution") {
+	case "", DistNormal:
+		return Normal{}, nil
+	case DistLaplace:
+		return Laplace{}, nil
+	case DistStudentT:
+		nu, err := strconv.ParseFloat(r.FormValue("distributionNu"), 64)
+		if err != nil || nu <= 2 || !finite(nu) {
+			return nil, fmt.Errorf("invalid input for distributionNu: degrees of freedom must be a finite number greater than 2")
+		}
+		return StudentT{nu}, nil
+	case DistSkewNormal:
+		skew, err := strconv.ParseFloat(r.FormValue("distributionSkew"), 64)
+		if err != nil || math.Abs(skew) >= 0.995 || !finite(skew) {
+			return nil, fmt.Errorf("invalid input for distributionSkew: skewness must be within ±0.995")
+		}
+		return SkewNormal{skew}, nil
+	case DistEmpirical:
+		errs, err := parseNumbers(r.FormValue("errorSamples"))
+		if err != nil {
+			return nil, fmt.Errorf("invalid input for errorSamples: %v", err)
+		}
+		d, err := NewEmpirical(errs)
+		if err != nil {
+			return nil, fmt.Errorf("invalid input for errorSamples: %v", err)
+		}
+		return d, nil
+	}
+	return nil, fmt.Errorf("unknown distribution %q", r.FormValue("distribution"))
+}
@@ -0,0 +1,110 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestDistributionCDF(t *testing.T) {
+	tests := []struct {
+		name                  string
+		dist                  ErrorDistribution
+		p, mean, stdDev, want float64
+	}{
+		{"Laplace above mean", Laplace{}, 5.5, 5, 1, 0.753466},
+		{"Laplace below mean", Laplace{}, 4, 5, 1, 0.121558},
+		{"Laplace at mean", Laplace{}, 0, 0, 2, 0.5},
+		{"Student-t ν=3", StudentT{3}, 1, 0, 1, 0.909155},
+		{"Student-t ν=3 tail", StudentT{3}, -2, 0, 1, 0.020260},
+		{"Student-t ν=5", StudentT{5}, 1, 0, 1, 0.873415},
+		{"Student-t ν=5 scaled", StudentT{5}, 2.5, 2, 0.5, 0.873415},
+		{"Student-t ν=30", StudentT{30}, -2, 0, 1, 0.023569},
+		{"skew-normal 0.5", SkewNormal{0.5}, -1, 0, 1, 0.152037},
+		{"skew-normal 0.5 at mean", SkewNormal{0.5}, 0, 0, 1, 0.537482},
+		{"skew-normal 0.5 upper", SkewNormal{0.5}, 1, 0, 1, 0.842600},
+		{"skew-normal -0.8", SkewNormal{-0.8}, -1, 0, 1, 0.159513},
+		{"skew-normal -0.8 at mean", SkewNormal{-0.8}, 0, 0, 1, 0.437676},
+		{"skew-normal -0.8 scaled", SkewNormal{-0.8}, 12, 10, 2, 0.853981},
+		{"skew-normal 0 is normal", SkewNormal{0}, 1, 0, 1, 0.841345},
+	}
+	for _, tt := range tests {
+		approx(t, tt.name, tt.dist.CDF(tt.p, tt.mean, tt.stdDev), tt.want, 1e-5)
+	}
+}
+
+func TestOwenT(t *testing.T) {
+	tests := []struct {
+		h, a, want float64
+	}{
+		{0, 1, 0.125},    // atan(a) / 2π
+		{1, 1, 0.066742}, // Φ(h)(1 - Φ(h)) / 2
+		{0.5, 2, 0.141581},
+		{1, 0.5, 0.043065},
+		{-1, -3, -0.079300},
+	}
+	for _, tt := range tests {
+		approx(t, "T", owenT(tt.h, tt.a), tt.want, 1e-6)
+	}
+}
+
+func TestEmpiricalCDF(t *testing.T) {
+	d, err := NewEmpirical([]float64{-3.1, -1.2, -0.8, -0.5, -0.3, -0.1, 0, 0.2, 0.4, 0.6, 0.9, 1.5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bins := len(d.Edges) - 1; bins != 5 {
+		t.Errorf("bins = %d, want 5", bins)
+	}
+	tests := []struct {
+		p, mean, stdDev, want float64
+	}{
+		{-5, 0, 1, 0},
+		{-1, 0, 1, 0.083333},
+		{0, 0, 1, 0.396739},
+		{0.5, 0, 1, 0.662757},
+		{3, 0, 1, 1},
+		// Shifted and scaled to the interval
+		{10, 10, 2, 0.396739},
+		{11, 10, 2, 0.662757},
+	}
+	for _, tt := range tests {
+		approx(t, "F", d.CDF(tt.p, tt.mean, tt.stdDev), tt.want, 1e-6)
+	}
+
+	for _, errs := range [][]float64{{1, 2, 3}, {2, 2, 2, 2, 2, 2, 2, 2, 2, 2}} {
+		if _, err := NewEmpirical(errs); err == nil {
+			t.Errorf("NewEmpirical(%v) accepted", errs)
+		}
+	}
+}
+
+func TestParseDistribution(t *testing.T) {
+	tests := []struct {
+		form url.Values
+		want string // empty when the input must be rejected
+	}{
+		{url.Values{}, Normal{}.String()},
+		{url.Values{"distribution": {DistLaplace}}, Laplace{}.String()},
+		{url.Values{"distribution": {DistStudentT}, "distributionNu": {"4"}}, StudentT{4}.String()},
+		{url.Values{"distribution": {DistStudentT}, "distributionNu": {"2"}}, ""},
+		{url.Values{"distribution": {DistSkewNormal}, "distributionSkew": {"-0.5"}}, SkewNormal{-0.5}.String()},
+		{url.Values{"distribution": {DistSkewNormal}, "distributionSkew": {"0.995"}}, ""},
+		{url.Values{"distribution": {DistStudentT}, "distributionNu": {"NaN"}}, ""},
+		{url.Values{"distribution": {DistStudentT}, "distributionNu": {"+Inf"}}, ""},
+		{url.Values{"distribution": {DistSkewNormal}, "distributionSkew": {"NaN"}}, ""},
+		{url.Values{"distribution": {DistEmpirical}, "errorSamples": {"1 2 3 4 5 6 7 8 9 NaN"}}, ""},
+		{url.Values{"distribution": {DistEmpirical}, "errorSamples": {"1 2 3"}}, ""},
+		{url.Values{"distribution": {"cauchy"}}, ""},
+	}
+	for _, tt := range tests {
+		dist, err := parseDistribution(postForm("/calculate", tt.form))
+		switch {
+		case tt.want == "" && err == nil:
+			t.Errorf("%v: accepted as %s", tt.form, dist)
+		case tt.want != "" && err != nil:
+			t.Errorf("%v: %v", tt.form, err)
+		case tt.want != "" && dist.String() != tt.want:
+			t.Errorf("%v: got %s, want %s", tt.form, dist, tt.want)
+		}
+	}
+}
@@ -0,0 +1,312 @@
+package main
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// One historical interval: forecast vs metered output
+type Observation struct {
+	Time     time.Time
+	Forecast float64
+	Actual   float64
+}
+
+// Timestamp layouts accepted in the history CSV
+var timeLayouts = []string{
+	time.RFC3339,
+	"2006-01-02T15:04",
+	"2006-01-02 15:04:05",
+	"2006-01-02 15:04",
+	"02.01.2006 15:04",
+}
+
+func parseTime(s string) (time.Time, error) {
+	for _, layout := range timeLayouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("unrecognised timestamp %q", s)
+}
+
+// Read a CSV upload. Semicolon-separated files with decimal commas (Ukrainian locale exports)
+// are accepted too; number parses a field according to the detected format.
+func readCSV(r io.Reader) (records [][]string, number func(string) (float64, error), err error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, nil, err
+	}
+	firstLine, _, _ := strings.Cut(string(data), "\n")
+	semicolon := strings.Contains(firstLine, ";")
+
+	reader := csv.NewReader(strings.NewReader(string(data)))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	if semicolon {
+		reader.Comma = ';'
+	}
+	records, err = reader.ReadAll()
+	if err != nil {
+		return nil, nil, err
+	}
+	number = func(s string) (float64, error) {
+		s = strings.TrimSpace(s)
+		if semicolon {
+			s = strings.Replace(s, ",", ".", 1)
+		}
+		return strconv.ParseFloat(s, 64)
+	}
+	return records, number, nil
+}
+
+// Parse a CSV with columns: timestamp, forecast, actual. A header row is skipped.
+func parseHistory(r io.Reader) ([]Observation, error) {
+	records, number, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var obs []Observation
+	for i, rec := range records {
+		if len(rec) < 3 {
+			return nil, fmt.Errorf("line %d: expected timestamp, forecast, actual", i+1)
+		}
+		t, err := parseTime(strings.TrimSpace(rec[0]))
+		if err != nil {
+			if i == 0 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		forecast, err1 := number(rec[1])
+		actual, err2 := number(rec[2])
+		if err1 != nil || err2 != nil || !finite(forecast) || !finite(actual) {
+			return nil, fmt.Errorf("line %d: forecast and actual must be finite numbers", i+1)
+		}
+		obs = append(obs, Observation{t, forecast, actual})
+	}
+	if len(obs) < 2 {
+		return nil, fmt.Errorf("at least two observations are required")
+	}
+	return obs, nil
+}
+
+// Grouping of observations for fitting
+const (
+	GroupAll    = "all"
+	GroupHour   = "hour"
+	GroupSeason = "season"
+)
+
+var seasons = []string{"Зима", "Весна", "Літо", "Осінь"}
+
+// Group key and label of an observation
+func groupOf(t time.Time, groupBy string) (int, string) {
+	switch groupBy {
+	case GroupHour:
+		return t.Hour(), fmt.Sprintf("%02d:00", t.Hour())
+	case GroupSeason:
+		s := int(t.Month()) % 12 / 3 // Dec-Feb = 0, Mar-May = 1, ...
+		return s, seasons[s]
+	default:
+		return 0, "Усі дані"
+	}
+}
+
+// Fitted error distribution for one group
+type ErrorFit struct {
+	Key      int
+	Label    string
+	N        int
+	Bias     float64 // Mean error actual - forecast, MW
+	StdDev   float64 // Standard deviation of the error around the bias, MW
+	Skewness float64
+	Kurtosis float64 // Excess kurtosis (0 for the normal distribution)
+	KS       float64 // Kolmogorov–Smirnov statistic against N(bias, σ)
+	PValue   float64 // Asymptotic p-value of the KS test
+	RMSE     float64
+
+	// Form values that apply this group's fit alone (e.g. one season); nil for the overall fit.
+	// The error samples are left out: the page would otherwise repeat the whole history in every group form
+	Values map[string]string
+}
+
+// Result of fitting the history
+type FitResult struct {
+	GroupBy string
+	Overall ErrorFit
+	Groups  []ErrorFit
+	Errors  []float64 // All errors actual - forecast, for the empirical distribution
+}
+
+// Fit bias and σ of the forecast errors overall and per group
+func fitErrors(obs []Observation, groupBy string) FitResult {
+	all := make([]float64, len(obs))
+	groups := map[int][]float64{}
+	labels := map[int]string{}
+	for i, o := range obs {
+		e := o.Actual - o.Forecast
+		all[i] = e
+		key, label := groupOf(o.Time, groupBy)
+		groups[key] = append(groups[key], e)
+		labels[key] = label
+	}
+
+	res := FitResult{GroupBy: groupBy, Overall: fitSample(all), Errors: all}
+	res.Overall.Label = "Усі дані"
+	if groupBy == GroupHour || groupBy == GroupSeason {
+		keys := make([]int, 0, len(groups))
+		for k := range groups {
+			keys = append(keys, k)
+		}
+		sort.Ints(keys)
+		for _, k := range keys {
+			fit := fitSample(groups[k])
+			fit.Key, fit.Label = k, labels[k]
+			fit.Values = fit.prefill()
+			res.Groups = append(res.Groups, fit)
+		}
+	}
+	return res
+}
+
+// Moments and KS goodness-of-fit of a sample against the fitted normal distribution
+func fitSample(errs []float64) ErrorFit {
+	n := float64(len(errs))
+	fit := ErrorFit{N: len(errs)}
+
+	sum, sumSq := 0.0, 0.0
+	for _, e := range errs {
+		sum += e
+		sumSq += e * e
+	}
+	fit.Bias = sum / n
+	fit.RMSE = math.Sqrt(sumSq / n)
+	if len(errs) < 2 {
+		return fit
+	}
+
+	m2, m3, m4 := 0.0, 0.0, 0.0
+	for _, e := range errs {
+		d := e - fit.Bias
+		m2 += d * d
+		m3 += d * d * d
+		m4 += d * d * d * d
+	}
+	fit.StdDev = math.Sqrt(m2 / (n - 1))
+	if m2 == 0 {
+		return fit
+	}
+	m2, m3, m4 = m2/n, m3/n, m4/n
+	fit.Skewness = m3 / math.Pow(m2, 1.5)
+	fit.Kurtosis = m4/(m2*m2) - 3
+
+	sorted := append([]float64(nil), errs...)
+	sort.Float64s(sorted)
+	for i, e := range sorted {
+		cdf := normalCDF(e, fit.Bias, fit.StdDev)
+		fit.KS = math.Max(fit.KS, math.Max(float64(i+1)/n-cdf, cdf-float64(i)/n))
+	}
+	fit.PValue = ksPValue(fit.KS, n)
+	return fit
+}
+
+// Asymptotic Kolmogorov distribution: P(D > d) with the Stephens small-sample correction
+func ksPValue(d, n float64) float64 {
+	sqrtN := math.Sqrt(n)
+	lambda := (sqrtN + 0.12 + 0.11/sqrtN) * d
+	if lambda < 0.2 {
+		return 1
+	}
+	p := 0.0
+	for k := 1.0; k <= 100; k++ {
+		term := 2 * math.Pow(-1, k-1) * math.Exp(-2*k*k*lambda*lambda)
+		p += term
+		if math.Abs(term) < 1e-10 {
+			break
+		}
+	}
+	return math.Min(math.Max(p, 0), 1)
+}
+
+// Form values pre-filled from the fit: overall σ and bias, hourly profiles when grouped by hour,
+// and the shape parameters and samples for the non-normal error distributions
+func (f FitResult) Prefill() map[string]string {
+	values := f.Overall.prefill()
+	samples := make([]string, len(f.Errors))
+	for i, e := range f.Errors {
+		samples[i] = strconv.FormatFloat(e, 'f', 3, 64)
+	}
+	values["errorSamples"] = strings.Join(samples, " ")
+	if f.GroupBy == GroupHour && len(f.Groups) == 24 {
+		sigmas := make([]string, 24)
+		biases := make([]string, 24)
+		for i, g := range f.Groups {
+			sigmas[i] = formatFit(g.StdDev)
+			biases[i] = formatFit(g.Bias)
+		}
+		values["currentStdDevProfile"] = strings.Join(sigmas, " ")
+		values["currentBiasProfile"] = strings.Join(biases, " ")
+	}
+	return values
+}
+
+// Scalar form values of one fitted sample: σ, bias and shape parameters
+func (fit ErrorFit) prefill() map[string]string {
+	values := map[string]string{
+		"currentStdDev":    formatFit(fit.StdDev),
+		"currentBias":      formatFit(fit.Bias),
+		"distributionSkew": formatFit(math.Max(-0.99, math.Min(0.99, fit.Skewness))),
+	}
+	if fit.Kurtosis > 0 {
+		// Excess kurtosis of Student-t is 6/(ν-4)
+		values["distributionNu"] = formatFit(4 + 6/fit.Kurtosis)
+	}
+	return values
+}
+
+func formatFit(v float64) string { return strconv.FormatFloat(v, 'f', 4, 64) }
+
+// Maximum size of an uploaded history file
+const maxHistorySize = 32 << 20
+
+// Handle the history upload, fit the errors and pre-fill the calculation form
+func fitHistory(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseMultipartForm(maxHistorySize); err != nil {
+		http.Error(w, "Invalid upload", http.StatusBadRequest)
+		return
+	}
+	file, _, err := r.FormFile("history")
+	if err != nil {
+		http.Error(w, "Missing history file", http.StatusBadRequest)
+		return
+	}
+	defer file.Close()
+
+	obs, err := parseHistory(file)
+	if err != nil {
+		http.Error(w, "Invalid history file: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	groupBy := r.FormValue("groupBy")
+	switch groupBy {
+	case GroupAll, GroupHour, GroupSeason:
+	default:
+		groupBy = GroupAll
+	}
+	fit := fitErrors(obs, groupBy)
+	render(w, PageData{Values: fit.Prefill(), Fit: &fit})
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFitErrorsSeasonPrefill(t *testing.T) {
+	obs := []Observation{}
+	add := func(date string, errs ...float64) {
+		for i, e := range errs {
+			tm, _ := time.Parse("2006-01-02", date)
+			obs = append(obs, Observation{Time: tm.Add(time.Duration(i) * time.Hour), Forecast: 10, Actual: 10 + e})
+		}
+	}
+	add("2024-01-15", 1, 3)
+	add("2024-07-15", -1, -1, -4)
+
+	fit := fitErrors(obs, GroupSeason)
+	if len(fit.Groups) != 2 {
+		t.Fatalf("got %d groups, want 2", len(fit.Groups))
+	}
+	tests := []struct {
+		label        string
+		bias, stdDev float64
+	}{
+		{"Зима", 2, 1.414214},
+		{"Літо", -2, 1.732051},
+	}
+	for i, tt := range tests {
+		g := fit.Groups[i]
+		if g.Label != tt.label {
+			t.Errorf("group %d label %q, want %q", i, g.Label, tt.label)
+		}
+		approx(t, tt.label+" bias", g.Bias, tt.bias, 1e-6)
+		approx(t, tt.label+" σ", g.StdDev, tt.stdDev, 1e-6)
+		if _, ok := g.Values["errorSamples"]; ok {
+			t.Errorf("%s form repeats the error samples", tt.label)
+		}
+		if g.Values["currentStdDev"] != formatFit(tt.stdDev) || g.Values["currentBias"] != formatFit(tt.bias) {
+			t.Errorf("%s prefill σ %s bias %s", tt.label, g.Values["currentStdDev"], g.Values["currentBias"])
+		}
+	}
+
+	// The overall fit fills the form; season profiles are not hourly profiles
+	values := fit.Prefill()
+	approx(t, "overall bias", fit.Overall.Bias, -0.4, 1e-9)
+	if _, ok := values["currentStdDevProfile"]; ok {
+		t.Error("season fit must not prefill an hourly σ profile")
+	}
+	if values["errorSamples"] != "1.000 3.000 -1.000 -1.000 -4.000" {
+		t.Errorf("overall samples %q", values["errorSamples"])
+	}
+
+	// A season's values submitted to the main page end up in the form
+	form := url.Values{}
+	for name, v := range fit.Groups[1].Values {
+		form.Set(name, v)
+	}
+	r := postForm("/", form)
+	r.ParseForm()
+	if got := formValues(r)["currentBias"]; got != "-2.0000" {
+		t.Errorf("submitted bias %q, want -2.0000", got)
+	}
+}
+
+func TestFitErrorsHourlyPrefill(t *testing.T) {
+	var obs []Observation
+	start := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	for day := 0; day < 2; day++ {
+		for h := 0; h < 24; h++ {
+			e := float64(h%3) + float64(day) // errors h%3 and h%3+1: bias h%3+0.5, σ √0.5
+			obs = append(obs, Observation{Time: start.Add(time.Duration(24*day+h) * time.Hour), Forecast: 5, Actual: 5 + e})
+		}
+	}
+	values := fitErrors(obs, GroupHour).Prefill()
+	sigmas := strings.Fields(values["currentStdDevProfile"])
+	biases := strings.Fields(values["currentBiasProfile"])
+	if len(sigmas) != 24 || len(biases) != 24 {
+		t.Fatalf("got %d σ and %d bias values, want 24", len(sigmas), len(biases))
+	}
+	if sigmas[5] != "0.7071" || biases[5] != "2.5000" {
+		t.Errorf("hour 5: σ %s bias %s, want 0.7071 2.5000", sigmas[5], biases[5])
+	}
+}
+
+func TestParseHistory(t *testing.T) {
+	tests := []struct {
+		name    string
+		csv     string
+		n       int
+		wantErr string
+	}{
+		{"header", "time,forecast,actual\n2024-01-01 10:00,5,6\n2024-01-01 11:00,5,4\n", 2, ""},
+		{"semicolons", "2024-01-01 10:00;5,5;6\n2024-01-01 11:00;5;4,25\n", 2, ""},
+		{"not a number", "2024-01-01 10:00,5,x\n2024-01-01 11:00,5,4\n", 0, "line 1"},
+		{"NaN forecast", "2024-01-01 10:00,5,6\n2024-01-01 11:00,NaN,4\n", 0, "line 2"},
+		{"infinite actual", "2024-01-01 10:00,5,6\n2024-01-01 11:00,5,4\n2024-01-01 12:00,5,-Inf\n", 0, "line 3"},
+		{"one observation", "2024-01-01 10:00,5,6\n", 0, "at least two"},
+	}
+	for _, tt := range tests {
+		obs, err := parseHistory(strings.NewReader(tt.csv))
+		switch {
+		case tt.wantErr == "" && (err != nil || len(obs) != tt.n):
+			t.Errorf("%s: %d observations, %v", tt.name, len(obs), err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: error %v, want one about %s", tt.name, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Variables the inverse mode can solve for
+const (
+	SolveSigma = "sigma" // σ2 of the improved forecast, the same for every interval
+	SolveBand  = "band"  // Symmetric tolerance band width in MW (or % in percent mode)
+)
+
+// Number of points on the profit curve
+const curvePoints = 100
+
+// Size of the profit curve chart in pixels
+const chartWidth, chartHeight = 360.0, 200.0
+
+// Find the σ2 or tolerance band at which the daily profit after improvement reaches Target
+type InverseProblem struct {
+	Variable string
+	Target   float64 // Daily final profit to reach; 0 is break-even
+	Max      float64 // Upper end of the searched range
+}
+
+// Parse the inverse mode fields; ok is false when no inverse problem was requested
+func parseInverse(r *http.Request) (InverseProblem, bool, error) {
+	prob := InverseProblem{Variable: r.FormValue("solveFor")}
+	switch prob.Variable {
+	case "":
+		return prob, false, nil
+	case SolveSigma, SolveBand:
+	default:
+		return prob, false, fmt.Errorf("unknown solveFor %q", prob.Variable)
+	}
+
+	var err error
+	if v := r.FormValue("targetProfit"); v != "" {
+		prob.Target, err = strconv.ParseFloat(v, 64)
+		if err != nil || !finite(prob.Target) {
+			return prob, false, fmt.Errorf("Invalid input for targetProfit")
+		}
+	}
+	if v := r.FormValue("solveMax"); v != "" {
+		prob.Max, err = strconv.ParseFloat(v, 64)
+		if err != nil || prob.Max <= 0 || !finite(prob.Max) {
+			return prob, false, fmt.Errorf("Invalid input for solveMax")
+		}
+	}
+	return prob, true, nil
+}
+
+// Point on the profit curve
+type CurvePoint struct {
+	X, Profit float64
+}
+
+// Solution of the inverse problem and the profit curve over the searched range
+type InverseResult struct {
+	InverseProblem
+	Unit                 string
+	Min                  float64 // Lower end of the searched range; above 0 for σ2
+	Solution             float64
+	Found                bool // False when the target is not reached anywhere in [Min, Max]
+	BandFixed            bool // σ2 is solved with the ±σ2 band held at the submitted σ2
+	ZeroBand             bool // The held band has zero width (e.g. σ2 = 0 was submitted), so profit does not depend on σ2
+	MinProfit, MaxProfit float64
+	Curve                []CurvePoint
+
+	// SVG chart: polyline points, target line and solution marker in pixels
+	Points             string
+	TargetY, SolutionX float64
+}
+
+// Solve for the σ2 or band width giving the target profit by scanning the range and bisecting the first crossing
+func solveInverse(p Profile, tolerance Tolerance, dist ErrorDistribution, pricing Pricing, prob InverseProblem) InverseResult {
+	res := InverseResult{InverseProblem: prob, Unit: "МВт"}
+
+	var profit func(x float64) float64
+	switch prob.Variable {
+	case SolveSigma:
+		if res.Max == 0 {
+			res.Max = 2 * math.Max(maxOf(p.StdDevBefore), maxOf(p.StdDevAfter))
+		}
+		// The band stays at the limits computed from the submitted σ2: with a ±σ2 tolerance
+		// a band moving with σ2 would make the in-band share independent of σ2
+		hours := p.IntervalHours()
+		lower, upper := make([]float64, len(p.Forecast)), make([]float64, len(p.Forecast))
+		for i, forecast := range p.Forecast {
+			lower[i], upper[i] = tolerance.Band(forecast, p.StdDevAfter[i])
+		}
+		res.BandFixed = tolerance.Mode == ToleranceSigma
+		res.ZeroBand = true
+		for i := range lower {
+			if upper[i] > lower[i] {
+				res.ZeroBand = false
+			}
+		}
+		// σ2 = 0 is a perfect forecast, a step rather than a limit of the curve; the scan starts above it
+		res.Min = res.Max / curvePoints
+		profit = func(x float64) float64 {
+			var after Settlement
+			for i, forecast := range p.Forecast {
+				after.Add(settle(dist, forecast, 0, x, lower[i], upper[i], hours, pricing))
+			}
+			return after.FinalProfit
+		}
+	case SolveBand:
+		t := tolerance
+		if t.Mode == TolerancePercent {
+			res.Unit = "%"
+			if res.Max == 0 {
+				res.Max = 100
+			}
+		} else {
+			t.Mode = ToleranceAbsolute
+			if res.Max == 0 {
+				res.Max = 4 * math.Max(maxOf(p.StdDevBefore), maxOf(p.StdDevAfter))
+			}
+		}
+		profit = func(x float64) float64 {
+			t.Lower, t.Upper = x, x
+			_, _, after := settleProfile(p, t, dist, pricing)
+			return after.FinalProfit
+		}
+	}
+	if res.Max == 0 {
+		res.Max = math.Max(1, maxOf(p.Forecast))
+		if prob.Variable == SolveSigma {
+			res.Min = res.Max / curvePoints
+		}
+	}
+
+	res.Curve = make([]CurvePoint, curvePoints+1)
+	for i := range res.Curve {
+		x := res.Min + (res.Max-res.Min)*float64(i)/curvePoints
+		res.Curve[i] = CurvePoint{x, profit(x)}
+	}
+	res.MinProfit, res.MaxProfit = res.Curve[0].Profit, res.Curve[0].Profit
+	for _, c := range res.Curve {
+		res.MinProfit = math.Min(res.MinProfit, c.Profit)
+		res.MaxProfit = math.Max(res.MaxProfit, c.Profit)
+	}
+
+	// A zero-width band leaves every interval outside it whatever σ2 is: the curve is flat, there is nothing to solve
+	for i := 1; i < len(res.Curve) && !res.Found && !res.ZeroBand; i++ {
+		lo, hi := res.Curve[i-1], res.Curve[i]
+		if (lo.Profit-prob.Target)*(hi.Profit-prob.Target) > 0 {
+			continue
+		}
+		a, b, fa := lo.X, hi.X, lo.Profit-prob.Target
+		for j := 0; j < 60; j++ {
+			mid := (a + b) / 2
+			fm := profit(mid) - prob.Target
+			if (fa < 0) == (fm < 0) {
+				a, fa = mid, fm
+			} else {
+				b = mid
+			}
+		}
+		res.Solution, res.Found = (a+b)/2, true
+	}
+
+	res.chart()
+	return res
+}
+
+// Lay out the SVG chart of the profit curve
+func (res *InverseResult) chart() {
+	lo := math.Min(res.MinProfit, res.Target)
+	hi := math.Max(res.MaxProfit, res.Target)
+	if hi == lo {
+		hi, lo = hi+1, lo-1
+	}
+	y := func(v float64) float64 { return chartHeight * (hi - v) / (hi - lo) }
+
+	points := make([]string, len(res.Curve))
+	for i, c := range res.Curve {
+		points[i] = fmt.Sprintf("%.1f,%.1f", res.chartX(c.X), y(c.Profit))
+	}
+	res.Points = strings.Join(points, " ")
+	res.TargetY = y(res.Target)
+	res.SolutionX = res.chartX(res.Solution)
+}
+
+// Horizontal chart position of x in the searched range
+func (res *InverseResult) chartX(x float64) float64 {
+	return chartWidth * (x - res.Min) / (res.Max - res.Min)
+}
+
+func maxOf(values []float64) float64 {
+	m := 0.0
+	for _, v := range values {
+		m = math.Max(m, v)
+	}
+	return m
+}
@@ -0,0 +1,94 @@
+package main
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSolveInverse(t *testing.T) {
+	// Flat 5 MW with σ1 = 1, σ2 = 0.25 and a symmetric 7 грн/MWh price: the day is worth 840 грн
+	p := Profile{Forecast: repeat(5, 24), StdDevBefore: repeat(1, 24), StdDevAfter: repeat(0.25, 24), Bias: repeat(0, 24)}
+	pricing := Pricing{EnergyCost: 7, SurplusPrice: 7, DeficitPrice: 7, SurplusMultiplier: 1, DeficitMultiplier: 1}
+	sigmaBand := Tolerance{Mode: ToleranceSigma}
+	absBand := Tolerance{Mode: ToleranceAbsolute, Lower: 0.25, Upper: 0.25}
+
+	tests := []struct {
+		name      string
+		tolerance Tolerance
+		prob      InverseProblem
+		found     bool
+		want      float64
+	}{
+		// The ±σ2 band is held at ±0.25 MW, so profit 840·(1 − 4Φ(−0.25/σ2)) depends on σ2
+		{"σ2 for the current profit", sigmaBand, InverseProblem{Variable: SolveSigma, Target: 306.918347}, true, 0.25},
+		{"σ2 for 500 грн", sigmaBand, InverseProblem{Variable: SolveSigma, Target: 500}, true, 0.196110},
+		{"σ2 with a fixed MW band", absBand, InverseProblem{Variable: SolveSigma, Target: 500}, true, 0.196110},
+		// Above the perfect-forecast profit: reported as not reachable, not as σ2 = 0
+		{"σ2 beyond full profit", sigmaBand, InverseProblem{Variable: SolveSigma, Target: 900}, false, 0},
+		{"band for the current profit", absBand, InverseProblem{Variable: SolveBand, Target: 306.918347}, true, 0.25},
+	}
+	for _, tt := range tests {
+		res := solveInverse(p, tt.tolerance, Normal{}, pricing, tt.prob)
+		if res.Found != tt.found {
+			t.Errorf("%s: found %v, want %v (profit %.2f – %.2f)", tt.name, res.Found, tt.found, res.MinProfit, res.MaxProfit)
+			continue
+		}
+		if tt.found {
+			approx(t, tt.name, res.Solution, tt.want, 1e-5)
+		}
+		if tt.prob.Variable == SolveSigma && res.Curve[0].X <= 0 {
+			t.Errorf("%s: σ2 scan starts at %g, want above 0", tt.name, res.Curve[0].X)
+		}
+	}
+}
+
+func TestSolveInverseZeroBand(t *testing.T) {
+	// σ2 = 0 submitted with a ±σ2 tolerance: the held band has no width and every interval is outside it
+	p := Profile{Forecast: repeat(5, 24), StdDevBefore: repeat(1, 24), StdDevAfter: repeat(0, 24), Bias: repeat(0, 24)}
+	pricing := Pricing{EnergyCost: 7, SurplusPrice: 7, DeficitPrice: 7, SurplusMultiplier: 1, DeficitMultiplier: 1}
+	res := solveInverse(p, Tolerance{Mode: ToleranceSigma}, Normal{}, pricing, InverseProblem{Variable: SolveSigma, Target: -840})
+	if !res.ZeroBand || res.Found {
+		t.Errorf("zero band %v, found %v; want a zero band and no solution", res.ZeroBand, res.Found)
+	}
+	approx(t, "min profit", res.MinProfit, -840, 1e-9)
+	approx(t, "max profit", res.MaxProfit, -840, 1e-9)
+
+	form := url.Values{
+		"dailyPower":    {"5"},
+		"currentStdDev": {"1"},
+		"futureStdDev":  {"0"},
+		"energyCost":    {"7"},
+		"solveFor":      {SolveSigma},
+		"targetProfit":  {"500"},
+	}
+	w := httptest.NewRecorder()
+	calculate(w, postForm("/calculate", form))
+	if !strings.Contains(w.Body.String(), "Коридор має нульову ширину (введено σ2 = 0)") {
+		t.Error("result page does not explain the zero-width band")
+	}
+}
+
+func TestParseInverse(t *testing.T) {
+	tests := []struct {
+		form    url.Values
+		ok      bool
+		wantErr bool
+	}{
+		{url.Values{}, false, false},
+		{url.Values{"solveFor": {SolveSigma}, "targetProfit": {"500"}}, true, false},
+		{url.Values{"solveFor": {SolveBand}, "targetProfit": {"-100"}, "solveMax": {"2"}}, true, false},
+		{url.Values{"solveFor": {SolveSigma}, "targetProfit": {"NaN"}}, false, true},
+		{url.Values{"solveFor": {SolveSigma}, "targetProfit": {"Inf"}}, false, true},
+		{url.Values{"solveFor": {SolveBand}, "solveMax": {"nan"}}, false, true},
+		{url.Values{"solveFor": {SolveBand}, "solveMax": {"+Inf"}}, false, true},
+		{url.Values{"solveFor": {"price"}}, false, true},
+	}
+	for _, tt := range tests {
+		_, ok, err := parseInverse(postForm("/calculate", tt.form))
+		if ok != tt.ok || (err != nil) != tt.wantErr {
+			t.Errorf("%v: ok %v, error %v", tt.form, ok, err)
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// Days per year used to annualise the daily profit difference
+const daysPerYear = 365
+
+// Energy price factors of the sensitivity table
+var priceFactors = []float64{0.5, 0.75, 1, 1.25, 1.5}
+
+// Cost of the improved forecasting system
+type Investment struct {
+	Capex        float64 // One-off cost, paid in year 0
+	Subscription float64 // Yearly cost
+	Lifetime     int     // Years
+	DiscountRate float64 // Percent per year
+}
+
+// Parse the investment fields; ok is false when no capex or subscription was entered
+func parseInvestment(r *http.Request) (Investment, bool, error) {
+	if r.FormValue("capex") == "" && r.FormValue("subscription") == "" {
+		return Investment{}, false, nil
+	}
+	inv := Investment{Lifetime: 10, DiscountRate: 10}
+	number := func(name string, v *float64) error {
+		s := r.FormValue(name)
+		if s == "" {
+			return nil
+		}
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil || x < 0 || !finite(x) {
+			return fmt.Errorf("Invalid input for %s", name)
+		}
+		*v = x
+		return nil
+	}
+	if err := number("capex", &inv.Capex); err != nil {
+		return inv, false, err
+	}
+	if err := number("subscription", &inv.Subscription); err != nil {
+		return inv, false, err
+	}
+	if err := number("discountRate", &inv.DiscountRate); err != nil {
+		return inv, false, err
+	}
+	if s := r.FormValue("lifetime"); s != "" {
+		years, err := strconv.Atoi(s)
+		if err != nil || years < 1 || years > 100 {
+			return inv, false, fmt.Errorf("Invalid input for lifetime")
+		}
+		inv.Lifetime = years
+	}
+	return inv, true, nil
+}
+
+// Appraisal of the investment for one daily profit difference
+type Appraisal struct {
+	DailyGain     float64 // FinalProfitAfter - FinalProfitBefore
+	AnnualBenefit float64
+	NetAnnual     float64 // Annual benefit minus subscription
+	NPV           float64
+	IRR           float64 // Percent; valid only if HasIRR
+	HasIRR        bool
+	Payback       float64 // Years; negative if not paid back within the lifetime
+	Discounted    float64 // Discounted payback, years; negative if never
+}
+
+// Net present value of -capex followed by Lifetime yearly net cash flows
+func (inv Investment) npv(rate, netAnnual float64) float64 {
+	npv := -inv.Capex
+	for year := 1; year <= inv.Lifetime; year++ {
+		npv += netAnnual / math.Pow(1+rate, float64(year))
+	}
+	return npv
+}
+
+// Appraise the investment for the given daily profit difference
+func (inv Investment) Appraise(dailyGain float64) Appraisal {
+	a := Appraisal{DailyGain: dailyGain, AnnualBenefit: dailyGain * daysPerYear, Payback: -1, Discounted: -1}
+	a.NetAnnual = a.AnnualBenefit - inv.Subscription
+	rate := inv.DiscountRate / 100
+	a.NPV = inv.npv(rate, a.NetAnnual)
+
+	// IRR by bisection: NPV decreases with the rate for a single outlay followed by equal inflows
+	if inv.Capex > 0 && a.NetAnnual > 0 {
+		lo, hi := -0.99, 10.0
+		if inv.npv(lo, a.NetAnnual) > 0 && inv.npv(hi, a.NetAnnual) < 0 {
+			for i := 0; i < 100; i++ {
+				mid := (lo + hi) / 2
+				if inv.npv(mid, a.NetAnnual) > 0 {
+					lo = mid
+				} else {
+					hi = mid
+				}
+			}
+			a.IRR, a.HasIRR = (lo+hi)/2*100, true
+		}
+	}
+
+	if a.NetAnnual > 0 {
+		if years := inv.Capex / a.NetAnnual; years <= float64(inv.Lifetime) {
+			a.Payback = years
+		}
+		// Discounted payback, interpolated within the year it is reached
+		remaining := inv.Capex
+		for year := 1; year <= inv.Lifetime; year++ {
+			flow := a.NetAnnual / math.Pow(1+rate, float64(year))
+			if flow >= remaining {
+				a.Discounted = float64(year-1) + remaining/flow
+				break
+			}
+			remaining -= flow
+		}
+	}
+	return a
+}
+
+// Sensitivity table row: appraisal at a scaled energy price
+type SensitivityRow struct {
+	PriceFactor float64
+	EnergyCost  float64
+	Appraisal
+}
+
+// Appraisal of the investment at the entered price plus the sensitivity table
+type InvestmentResult struct {
+	Investment
+	Base        Appraisal
+	Sensitivity []SensitivityRow
+}
+
+// Appraise the investment; dailyGain returns the profit difference at scaled prices
+func appraiseInvestment(inv Investment, pricing Pricing, dailyGain func(Pricing) float64) InvestmentResult {
+	res := InvestmentResult{Investment: inv, Base: inv.Appraise(dailyGain(pricing))}
+	for _, f := range priceFactors {
+		scaled := pricing.Scale(f)
+		res.Sensitivity = append(res.Sensitivity, SensitivityRow{f, scaled.EnergyCost, inv.Appraise(dailyGain(scaled))})
+	}
+	return res
+}
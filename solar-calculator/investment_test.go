@@ -0,0 +1,83 @@
+package main
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestAppraise(t *testing.T) {
+	tests := []struct {
+		name                string
+		inv                 Investment
+		dailyGain           float64
+		netAnnual, npv      float64
+		irr                 float64 // Percent, checked only when hasIRR
+		hasIRR              bool
+		payback, discounted float64
+	}{
+		{"paid back", Investment{100000, 5000, 10, 10}, 100, 31500, 93553.863830, 29.039077, true, 3.174603, 4.007630},
+		{"no subscription", Investment{50000, 0, 5, 8}, 40, 14600, 8293.566541, 14.102293, true, 3.424658, 4.165345},
+		{"subscription exceeds the gain", Investment{100000, 5000, 10, 10}, 10, -1350, -108295.165593, 0, false, -1, -1},
+		// Not paid back within the lifetime: the IRR is negative
+		{"not paid back", Investment{1000, 0, 2, 0}, 1, 365, -270, -18.638492, true, -1, -1},
+	}
+	for _, tt := range tests {
+		a := tt.inv.Appraise(tt.dailyGain)
+		approx(t, tt.name+" net annual", a.NetAnnual, tt.netAnnual, 1e-6)
+		approx(t, tt.name+" NPV", a.NPV, tt.npv, 1e-5)
+		if a.HasIRR != tt.hasIRR {
+			t.Errorf("%s: HasIRR = %v, want %v", tt.name, a.HasIRR, tt.hasIRR)
+		} else if tt.hasIRR {
+			approx(t, tt.name+" IRR", a.IRR, tt.irr, 1e-5)
+		}
+		approx(t, tt.name+" payback", a.Payback, tt.payback, 1e-6)
+		approx(t, tt.name+" discounted payback", a.Discounted, tt.discounted, 1e-6)
+	}
+}
+
+func TestAppraiseInvestmentSensitivity(t *testing.T) {
+	inv := Investment{Capex: 100000, Subscription: 5000, Lifetime: 10, DiscountRate: 10}
+	pricing := Pricing{EnergyCost: 7, SurplusPrice: 7, DeficitPrice: 7, SurplusMultiplier: 1, DeficitMultiplier: 1}
+	// A gain proportional to the price, 100 at 7 per MWh
+	res := appraiseInvestment(inv, pricing, func(p Pricing) float64 { return p.EnergyCost / 7 * 100 })
+
+	approx(t, "base NPV", res.Base.NPV, 93553.863830, 1e-5)
+	if len(res.Sensitivity) != len(priceFactors) {
+		t.Fatalf("%d sensitivity rows, want %d", len(res.Sensitivity), len(priceFactors))
+	}
+	for _, row := range res.Sensitivity {
+		approx(t, "energy cost", row.EnergyCost, 7*row.PriceFactor, 1e-9)
+		approx(t, "daily gain", row.DailyGain, 100*row.PriceFactor, 1e-9)
+		if row.PriceFactor == 1 && row.NPV != res.Base.NPV {
+			t.Errorf("row at the entered price: NPV %v, want %v", row.NPV, res.Base.NPV)
+		}
+	}
+}
+
+func TestParseInvestment(t *testing.T) {
+	tests := []struct {
+		form    url.Values
+		want    Investment
+		ok      bool
+		wantErr bool
+	}{
+		{url.Values{}, Investment{}, false, false},
+		{url.Values{"capex": {"1000"}}, Investment{1000, 0, 10, 10}, true, false},
+		{url.Values{"subscription": {"50"}, "lifetime": {"5"}, "discountRate": {"7.5"}}, Investment{0, 50, 5, 7.5}, true, false},
+		{url.Values{"capex": {"-1"}}, Investment{}, false, true},
+		{url.Values{"capex": {"NaN"}}, Investment{}, false, true},
+		{url.Values{"subscription": {"+Inf"}}, Investment{}, false, true},
+		{url.Values{"capex": {"1000"}, "discountRate": {"nan"}}, Investment{}, false, true},
+		{url.Values{"capex": {"1000"}, "lifetime": {"0"}}, Investment{}, false, true},
+	}
+	for _, tt := range tests {
+		inv, ok, err := parseInvestment(postForm("/calculate", tt.form))
+		if (err != nil) != tt.wantErr || ok != tt.ok {
+			t.Errorf("%v: ok %v, error %v", tt.form, ok, err)
+			continue
+		}
+		if ok && inv != tt.want {
+			t.Errorf("%v: got %+v, want %+v", tt.form, inv, tt.want)
+		}
+	}
+}
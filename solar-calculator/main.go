@@ -13,43 +13,159 @@ import (
 type CalculationResult struct {
 	W1, W2, ProfitBefore, PenaltyBefore, FinalProfitBefore float64
 	W3, W4, ProfitAfter, PenaltyAfter, FinalProfitAfter    float64
+
+	// Tolerance band and the share of energy inside it before/after improvement
+	Tolerance               Tolerance
+	Distribution            ErrorDistribution
+	BandLower, BandUpper    float64
+	ShareBefore, ShareAfter float64
+
+	// Prices and the revenue breakdown by in-band, surplus and deficit energy
+	Pricing       Pricing
+	Before, After Settlement
+
+	// Per-interval breakdown when a forecast profile is submitted
+	Intervals []IntervalResult
 }
 
-// Normal distribution function
-func normalDistribution(p, Pc, stdDev float64) float64 {
-	return (1 / (stdDev * math.Sqrt(2*math.Pi))) * math.Exp(-math.Pow(p-Pc, 2)/(2*math.Pow(stdDev, 2)))
+// Whether any per-interval profile was submitted (the breakdown table is shown then)
+func isProfile(r *http.Request) bool {
+	for _, name := range []string{"forecastProfile", "currentStdDevProfile", "futureStdDevProfile", "currentBiasProfile"} {
+		if r.FormValue(name) != "" {
+			return true
+		}
+	}
+	return false
 }
 
-// Numerical integration to approximate the probability
-func integrateNormalDistribution(Pc, stdDev, P_lower, P_upper float64) float64 {
-	n := 1000
-	step := (P_upper - P_lower) / float64(n)
-	area := 0.0
-	for i := 0; i < n; i++ {
-		x1 := P_lower + float64(i)*step
-		x2 := P_lower + float64(i+1)*step
-		y1 := normalDistribution(x1, Pc, stdDev)
-		y2 := normalDistribution(x2, Pc, stdDev)
-		area += 0.5 * (y1 + y2) * step
-	}
-	return area
+// Whether x is a real number: ParseFloat accepts "NaN" and "Inf", and NaN passes every range check
+func finite(x float64) bool {
+	return !math.IsNaN(x) && !math.IsInf(x, 0)
 }
 
-// Serve the main page
-func serveHome(w http.ResponseWriter, r *http.Request) {
-	tmpl, err := template.ParseFiles("index.html")
+// Parse a forecast error σ: a finite non-negative number
+func parseStdDev(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil || !finite(v) {
+		return 0, fmt.Errorf("not a number")
+	}
+	if v < 0 {
+		return 0, fmt.Errorf("σ must not be negative")
+	}
+	return v, nil
+}
+
+// Build the forecast profile: either the submitted 24/96-value profile
+// or a flat dailyPower for every hour, with optional per-interval σ and bias
+func parseForecast(r *http.Request) (Profile, error) {
+	// σ = 0 means a perfect forecast; negative or NaN values are rejected rather than treated as one
+	currentStdDev, err := parseStdDev(r.FormValue("currentStdDev"))
+	if err != nil && r.FormValue("currentStdDevProfile") == "" {
+		return Profile{}, fmt.Errorf("Invalid input for currentStdDev: %v", err)
+	}
+
+	futureStdDev, err := parseStdDev(r.FormValue("futureStdDev"))
+	if err != nil && r.FormValue("futureStdDevProfile") == "" {
+		return Profile{}, fmt.Errorf("Invalid input for futureStdDev: %v", err)
+	}
+
+	// Optional mean error of the current forecast (e.g. fitted from history)
+	currentBias := 0.0
+	if v := r.FormValue("currentBias"); v != "" {
+		currentBias, err = strconv.ParseFloat(v, 64)
+		if err != nil || !finite(currentBias) {
+			return Profile{}, fmt.Errorf("Invalid input for currentBias")
+		}
+	}
+
+	var p Profile
+	if r.FormValue("forecastProfile") == "" {
+		dailyPower, err := strconv.ParseFloat(r.FormValue("dailyPower"), 64)
+		if err != nil || !finite(dailyPower) {
+			return Profile{}, fmt.Errorf("Invalid input for dailyPower")
+		}
+		// Flat forecast; σ and bias may still vary by hour
+		p.Forecast = repeat(dailyPower, 24)
+	} else {
+		p.Forecast, err = parseProfile(r.FormValue("forecastProfile"))
+		if err != nil {
+			return p, fmt.Errorf("Invalid input for forecastProfile: %v", err)
+		}
+		if n := len(p.Forecast); n != 24 && n != 96 {
+			return p, fmt.Errorf("Invalid input for forecastProfile: expected 24 hourly or 96 15-minute values, got %d", n)
+		}
+	}
+	p.StdDevBefore, err = parseProfileOrScalar(r.FormValue("currentStdDevProfile"), len(p.Forecast), currentStdDev)
+	if err != nil {
+		return p, fmt.Errorf("Invalid input for currentStdDevProfile: %v", err)
+	}
+	p.StdDevAfter, err = parseProfileOrScalar(r.FormValue("futureStdDevProfile"), len(p.Forecast), futureStdDev)
+	if err != nil {
+		return p, fmt.Errorf("Invalid input for futureStdDevProfile: %v", err)
+	}
+	p.Bias, err = parseSignedProfileOrScalar(r.FormValue("currentBiasProfile"), len(p.Forecast), currentBias)
+	if err != nil {
+		return p, fmt.Errorf("Invalid input for currentBiasProfile: %v", err)
+	}
+	return p, nil
+}
+
+// Template helpers
+var templateFuncs = template.FuncMap{
+	"percent": func(x float64) float64 { return x * 100 },
+}
+
+// Load index.html with the template helpers
+func loadTemplate() (*template.Template, error) {
+	return template.New("index.html").Funcs(templateFuncs).ParseFiles("index.html")
+}
+
+// Data passed to index.html
+type PageData struct {
+	Values     map[string]string // Form values to pre-fill
+	Result     *CalculationResult
+	Simulation *SimulationResult
+	Investment *InvestmentResult
+	Inverse    *InverseResult
+	Battery    *BatteryResult
+	Portfolio  *PortfolioResult
+	Yield      *YieldResult
+	Wind       *WindResult
+	Fit        *FitResult
+}
+
+// Copy submitted (or query) values so the form keeps them after a calculation
+func formValues(r *http.Request) map[string]string {
+	values := map[string]string{}
+	for name, v := range r.Form {
+		if len(v) > 0 {
+			values[name] = v[0]
+		}
+	}
+	return values
+}
+
+// Load and execute the template with data
+func render(w http.ResponseWriter, data PageData) {
+	tmpl, err := loadTemplate()
 	if err != nil {
 		http.Error(w, "Error loading template", http.StatusInternalServerError)
 		log.Println("Template parsing error:", err)
 		return
 	}
 
-	err = tmpl.Execute(w, nil) // No data needed on initial load
+	err = tmpl.Execute(w, data)
 	if err != nil {
 		log.Println("Error executing template:", err)
 	}
 }
 
+// Serve the main page; query parameters pre-fill the form (e.g. from a fitted history)
+func serveHome(w http.ResponseWriter, r *http.Request) {
+	r.ParseForm()
+	render(w, PageData{Values: formValues(r)})
+}
+
 // Handle form submission and perform calculations
 func calculate(w http.ResponseWriter, r *http.Request) {
 	if r.Method != http.MethodPost {
@@ -58,68 +174,100 @@ func calculate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Parse form values
-	dailyPower, err := strconv.ParseFloat(r.FormValue("dailyPower"), 64)
+	profile, err := parseForecast(r)
 	if err != nil {
-		http.Error(w, "Invalid input for dailyPower", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	currentStdDev, err := strconv.ParseFloat(r.FormValue("currentStdDev"), 64)
+	pricing, err := parsePricing(r)
 	if err != nil {
-		http.Error(w, "Invalid input for currentStdDev", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	futureStdDev, err := strconv.ParseFloat(r.FormValue("futureStdDev"), 64)
+	tolerance, err := parseTolerance(r)
 	if err != nil {
-		http.Error(w, "Invalid input for futureStdDev", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	energyCost, err := strconv.ParseFloat(r.FormValue("energyCost"), 64)
+	dist, err := parseDistribution(r)
 	if err != nil {
-		http.Error(w, "Invalid input for energyCost", http.StatusBadRequest)
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
-	// Perform calculations
-	P_lower := dailyPower - futureStdDev
-	P_upper := dailyPower + futureStdDev
-
-	deltaW1 := integrateNormalDistribution(dailyPower, currentStdDev, P_lower, P_upper)
-	W1 := dailyPower * 24 * deltaW1
-	profitBefore := W1 * energyCost
-
-	W2 := dailyPower * 24 * (1 - deltaW1)
-	penaltyBefore := W2 * energyCost
-	finalProfitBefore := profitBefore - penaltyBefore
-
-	deltaW2 := integrateNormalDistribution(dailyPower, futureStdDev, P_lower, P_upper)
-	W3 := dailyPower * 24 * deltaW2
-	profitAfter := W3 * energyCost
-
-	W4 := dailyPower * 24 * (1 - deltaW2)
-	penaltyAfter := W4 * energyCost
-	finalProfitAfter := profitAfter - penaltyAfter
+	// Perform calculations for every interval of the day
+	intervals, before, after := settleProfile(profile, tolerance, dist, pricing)
 
 	// Create result struct
 	result := CalculationResult{
-		W1, W2, profitBefore, penaltyBefore, finalProfitBefore,
-		W3, W4, profitAfter, penaltyAfter, finalProfitAfter,
+		W1:                before.InBand,
+		W2:                before.Imbalance,
+		ProfitBefore:      before.Profit,
+		PenaltyBefore:     before.Penalty,
+		FinalProfitBefore: before.FinalProfit,
+		W3:                after.InBand,
+		W4:                after.Imbalance,
+		ProfitAfter:       after.Profit,
+		PenaltyAfter:      after.Penalty,
+		FinalProfitAfter:  after.FinalProfit,
+		Tolerance:         tolerance,
+		Distribution:      dist,
+		BandLower:         intervals[0].BandLower,
+		BandUpper:         intervals[0].BandUpper,
+		ShareBefore:       before.Share,
+		ShareAfter:        after.Share,
+		Pricing:           pricing,
+		Before:            before,
+		After:             after,
+	}
+	if isProfile(r) {
+		result.Intervals = intervals
 	}
 
-	// Load and execute template with data
-	tmpl, err := template.ParseFiles("index.html")
+	data := PageData{Values: formValues(r), Result: &result}
+	sim, simulated, err := parseSimulation(r)
 	if err != nil {
-		http.Error(w, "Error loading template", http.StatusInternalServerError)
-		log.Println("Template parsing error:", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if simulated {
+		simulation := simulate(profile, tolerance, dist, pricing, sim)
+		data.Simulation = &simulation
+	}
+	if inv, ok, err := parseInvestment(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
+	} else if ok {
+		investment := appraiseInvestment(inv, pricing, func(pricing Pricing) float64 {
+			_, before, after := settleProfile(profile, tolerance, dist, pricing)
+			return after.FinalProfit - before.FinalProfit
+		})
+		data.Investment = &investment
+	}
+	if prob, ok, err := parseInverse(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if ok {
+		inverse := solveInverse(profile, tolerance, dist, pricing, prob)
+		data.Inverse = &inverse
 	}
 
-	err = tmpl.Execute(w, result)
-	if err != nil {
-		log.Println("Error executing template:", err)
+	if b, ok, err := parseBattery(r); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	} else if ok {
+		if !simulated {
+			sim = Simulation{Days: batteryDays, Seed: 1}
+		}
+		sim.Days = min(sim.Days, maxBatteryDays)
+		battery := evaluateBattery(profile, tolerance, dist, pricing, b, sim)
+		data.Battery = &battery
 	}
+
+	render(w, data)
 }
 
 func main() {
@@ -129,6 +277,18 @@ func main() {
 	// Handle form submission
 	http.HandleFunc("/calculate", calculate)
 
+	// Settle several plants as one balancing group
+	http.HandleFunc("/portfolio", portfolio)
+
+	// Model the hourly PV output to use as the forecast profile
+	http.HandleFunc("/yield", pvYield)
+
+	// Imbalance calculation for a wind plant
+	http.HandleFunc("/wind", windCalculate)
+
+	// Fit forecast error distribution from an uploaded history CSV
+	http.HandleFunc("/fit", fitHistory)
+
 	fmt.Println("Server running at http://localhost:8080")
 	log.Fatal(http.ListenAndServe(":8080", nil))
 }
@@ -0,0 +1,286 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Plant types of a portfolio
+const (
+	PlantSolar = "solar"
+	PlantWind  = "wind"
+)
+
+// One plant of the balancing group
+type Plant struct {
+	Name     string
+	Kind     string
+	Forecast []float64 // MW per interval
+	StdDev   []float64 // Forecast error σ per interval
+}
+
+// Parse plants, one per line: "name; solar|wind; forecast; σ".
+// Forecast and σ are either a single value or a 24/96-value profile.
+func parsePlants(s string) ([]Plant, error) {
+	var plants []Plant
+	for i, line := range strings.Split(s, "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		fields := strings.Split(line, ";")
+		if len(fields) != 4 {
+			return nil, fmt.Errorf("line %d: expected name; type; forecast; σ", i+1)
+		}
+		p := Plant{Name: strings.TrimSpace(fields[0]), Kind: strings.TrimSpace(fields[1])}
+		if p.Kind != PlantSolar && p.Kind != PlantWind {
+			return nil, fmt.Errorf("line %d: unknown plant type %q", i+1, p.Kind)
+		}
+		var err error
+		if p.Forecast, err = parseProfile(fields[2]); err != nil || len(p.Forecast) == 0 {
+			return nil, fmt.Errorf("line %d: invalid forecast", i+1)
+		}
+		if p.StdDev, err = parseProfile(fields[3]); err != nil || len(p.StdDev) == 0 {
+			return nil, fmt.Errorf("line %d: invalid σ", i+1)
+		}
+		plants = append(plants, p)
+	}
+	if len(plants) < 2 {
+		return nil, fmt.Errorf("at least two plants are required")
+	}
+
+	// Bring every plant to the same number of intervals (24 if all forecasts are flat)
+	n := 24
+	for _, p := range plants {
+		if len(p.Forecast) > 1 {
+			n = len(p.Forecast)
+			break
+		}
+	}
+	if n != 24 && n != 96 {
+		return nil, fmt.Errorf("expected 24 hourly or 96 15-minute forecast values, got %d", n)
+	}
+	for i := range plants {
+		var err error
+		if plants[i].Forecast, err = broadcast(plants[i].Forecast, n, 0); err != nil {
+			return nil, fmt.Errorf("%s: forecast: %v", plants[i].Name, err)
+		}
+		if plants[i].StdDev, err = broadcast(plants[i].StdDev, n, 0); err != nil {
+			return nil, fmt.Errorf("%s: σ: %v", plants[i].Name, err)
+		}
+	}
+	return plants, nil
+}
+
+// Parse the correlation matrix of forecast errors (one row per line); an empty
+// matrix uses the same coefficient for every pair of plants
+func parseCorrelations(s string, n int, uniform float64) ([][]float64, error) {
+	corr := make([][]float64, n)
+	if strings.TrimSpace(s) == "" {
+		if uniform < -1 || uniform > 1 || !finite(uniform) {
+			return nil, fmt.Errorf("correlation must be within [-1, 1]")
+		}
+		for i := range corr {
+			corr[i] = repeat(uniform, n)
+			corr[i][i] = 1
+		}
+	} else {
+		rows := strings.Split(strings.TrimSpace(s), "\n")
+		if len(rows) != n {
+			return nil, fmt.Errorf("expected %d rows, got %d", n, len(rows))
+		}
+		for i, row := range rows {
+			values, err := parseNumbers(row)
+			if err != nil || len(values) != n {
+				return nil, fmt.Errorf("row %d: expected %d numbers", i+1, n)
+			}
+			corr[i] = values
+		}
+		for i := range corr {
+			if math.Abs(corr[i][i]-1) > 1e-9 {
+				return nil, fmt.Errorf("diagonal element %d must be 1", i+1)
+			}
+			for j := range corr {
+				if math.Abs(corr[i][j]) > 1 || math.Abs(corr[i][j]-corr[j][i]) > 1e-9 {
+					return nil, fmt.Errorf("matrix must be symmetric with elements within [-1, 1]")
+				}
+			}
+		}
+	}
+	if !positiveSemidefinite(corr) {
+		return nil, fmt.Errorf("matrix is not positive semidefinite")
+	}
+	return corr, nil
+}
+
+// Cholesky test with a small tolerance for singular (e.g. perfectly correlated) matrices
+func positiveSemidefinite(a [][]float64) bool {
+	n := len(a)
+	l := make([][]float64, n)
+	for i := range l {
+		l[i] = make([]float64, n)
+		for j := 0; j <= i; j++ {
+			sum := a[i][j]
+			for k := 0; k < j; k++ {
+				sum -= l[i][k] * l[j][k]
+			}
+			switch {
+			case i == j:
+				if sum < -1e-9 || math.IsNaN(sum) {
+					return false
+				}
+				l[i][i] = math.Sqrt(math.Max(sum, 0))
+			case l[j][j] > 1e-12:
+				l[i][j] = sum / l[j][j]
+			case math.Abs(sum) > 1e-9:
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// Settlement of one plant on its own
+type PlantResult struct {
+	Plant
+	Settlement
+}
+
+// Plants of one type settled as their own balancing group
+type KindResult struct {
+	Kind   string
+	Plants int
+	Settlement
+}
+
+// Portfolio settled separately per plant and as one balancing group
+type PortfolioResult struct {
+	Plants       []PlantResult
+	Kinds        []KindResult // Solar-only and wind-only groups when both types are present
+	Correlations [][]float64
+	Distribution ErrorDistribution
+	Separate     Settlement // Sum of the plants settled one by one
+	Aggregate    Settlement // Balancing group settled on the summed forecast
+	Forecast     []float64  // Aggregated forecast per interval, MW
+	StdDev       []float64  // Aggregated error σ per interval, MW
+
+	// Band of the group per interval: the sum of the plants' own bands
+	BandLower, BandUpper []float64
+
+	NettingBenefit   float64 // Aggregate minus separate final profit
+	PenaltyReduction float64
+}
+
+// Summed forecast, σ and band of a balancing group per interval, and its settlement
+type group struct {
+	Forecast, StdDev     []float64
+	BandLower, BandUpper []float64
+	Settlement
+}
+
+// Settle the plants with the given indices as one group (one index settles a single plant).
+// Forecasts add up and σ² = Σ ρij σi σj; the group keeps the tolerance of each member,
+// so its band is the sum of the plants' bands, each computed from the plant's own forecast and σ.
+func settleGroup(plants []Plant, members []int, corr [][]float64, tolerance Tolerance, dist ErrorDistribution, pricing Pricing) group {
+	n := len(plants[0].Forecast)
+	hours := 24 / float64(n)
+	g := group{Forecast: make([]float64, n), StdDev: make([]float64, n), BandLower: make([]float64, n), BandUpper: make([]float64, n)}
+	for t := 0; t < n; t++ {
+		variance := 0.0
+		for _, i := range members {
+			a := plants[i]
+			lower, upper := tolerance.Band(a.Forecast[t], a.StdDev[t])
+			g.Forecast[t] += a.Forecast[t]
+			g.BandLower[t] += lower
+			g.BandUpper[t] += upper
+			for _, j := range members {
+				variance += corr[i][j] * a.StdDev[t] * plants[j].StdDev[t]
+			}
+		}
+		g.StdDev[t] = math.Sqrt(math.Max(variance, 0))
+		g.Add(settle(dist, g.Forecast[t], 0, g.StdDev[t], g.BandLower[t], g.BandUpper[t], hours, pricing))
+	}
+	return g
+}
+
+// Settle every plant on its own, every plant type as a group and the whole portfolio as one group
+func calculatePortfolio(plants []Plant, corr [][]float64, tolerance Tolerance, dist ErrorDistribution, pricing Pricing) PortfolioResult {
+	res := PortfolioResult{Correlations: corr, Distribution: dist}
+
+	all := make([]int, len(plants))
+	byKind := map[string][]int{}
+	for i, p := range plants {
+		s := settleGroup(plants, []int{i}, corr, tolerance, dist, pricing).Settlement
+		res.Plants = append(res.Plants, PlantResult{p, s})
+		res.Separate.Add(s)
+		all[i] = i
+		byKind[p.Kind] = append(byKind[p.Kind], i)
+	}
+
+	if len(byKind) > 1 {
+		for _, kind := range []string{PlantSolar, PlantWind} {
+			g := settleGroup(plants, byKind[kind], corr, tolerance, dist, pricing)
+			res.Kinds = append(res.Kinds, KindResult{kind, len(byKind[kind]), g.Settlement})
+		}
+	}
+
+	g := settleGroup(plants, all, corr, tolerance, dist, pricing)
+	res.Aggregate = g.Settlement
+	res.Forecast, res.StdDev = g.Forecast, g.StdDev
+	res.BandLower, res.BandUpper = g.BandLower, g.BandUpper
+
+	res.NettingBenefit = res.Aggregate.FinalProfit - res.Separate.FinalProfit
+	res.PenaltyReduction = res.Separate.Penalty - res.Aggregate.Penalty
+	return res
+}
+
+// Handle the portfolio form
+func portfolio(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	plants, err := parsePlants(r.FormValue("plants"))
+	if err != nil {
+		http.Error(w, "Invalid input for plants: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	uniform := 0.0
+	if v := r.FormValue("correlation"); v != "" {
+		uniform, err = strconv.ParseFloat(v, 64)
+		if err != nil {
+			http.Error(w, "Invalid input for correlation", http.StatusBadRequest)
+			return
+		}
+	}
+	corr, err := parseCorrelations(r.FormValue("correlations"), len(plants), uniform)
+	if err != nil {
+		http.Error(w, "Invalid input for correlations: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pricing, err := parsePricing(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tolerance, err := parseTolerance(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	dist, err := parseDistribution(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := calculatePortfolio(plants, corr, tolerance, dist, pricing)
+	render(w, PageData{Values: formValues(r), Portfolio: &res})
+}
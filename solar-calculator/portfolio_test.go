@@ -0,0 +1,92 @@
+package main
+
+import (
+	"math"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestCalculatePortfolio(t *testing.T) {
+	// Two flat 5 MW plants with σ = 1 and a symmetric 7 грн/MWh price
+	plants := []Plant{
+		{Name: "СЕС-1", Kind: PlantSolar, Forecast: repeat(5, 24), StdDev: repeat(1, 24)},
+		{Name: "ВЕС-1", Kind: PlantWind, Forecast: repeat(5, 24), StdDev: repeat(1, 24)},
+	}
+	pricing := Pricing{EnergyCost: 7, SurplusPrice: 7, DeficitPrice: 7, SurplusMultiplier: 1, DeficitMultiplier: 1}
+	sigmaBand := Tolerance{Mode: ToleranceSigma}
+
+	tests := []struct {
+		name                         string
+		rho                          float64
+		tolerance                    Tolerance
+		dist                         ErrorDistribution
+		separate, aggregate, netting float64
+	}{
+		// Group band ±(σ1 + σ2) = ±2 MW against the group σ = √(2 + 2ρ)
+		{"independent", 0, sigmaBand, Normal{}, 613.836694, 1151.474664, 537.637971},
+		{"ρ = 0.3", 0.3, sigmaBand, Normal{}, 613.836694, 958.114257, 344.277563},
+		{"fully correlated", 1, sigmaBand, Normal{}, 613.836694, 613.836694, 0},
+		{"±0.5 MW per plant", 0, Tolerance{Mode: ToleranceAbsolute, Lower: 0.5, Upper: 0.5}, Normal{}, -393.372260, 68.879589, 462.251850},
+		{"Laplace errors", 0, sigmaBand, Laplace{}, 863.127772, 1225.273448, 362.145676},
+	}
+	for _, tt := range tests {
+		corr := [][]float64{{1, tt.rho}, {tt.rho, 1}}
+		res := calculatePortfolio(plants, corr, tt.tolerance, tt.dist, pricing)
+		approx(t, tt.name+" separate", res.Separate.FinalProfit, tt.separate, 1e-5)
+		approx(t, tt.name+" aggregate", res.Aggregate.FinalProfit, tt.aggregate, 1e-5)
+		approx(t, tt.name+" netting", res.NettingBenefit, tt.netting, 1e-5)
+	}
+
+	// One solar and one wind plant: each type forms a one-plant group equal to the plant itself
+	res := calculatePortfolio(plants, [][]float64{{1, 0}, {0, 1}}, sigmaBand, Normal{}, pricing)
+	if len(res.Kinds) != 2 || res.Kinds[0].Kind != PlantSolar || res.Kinds[1].Kind != PlantWind {
+		t.Fatalf("kinds = %+v", res.Kinds)
+	}
+	approx(t, "solar group", res.Kinds[0].FinalProfit, res.Plants[0].FinalProfit, 1e-9)
+	approx(t, "group band", res.BandUpper[0]-res.BandLower[0], 4, 1e-9)
+
+	// Plants of one type only: no per-type breakdown
+	plants[1].Kind = PlantSolar
+	if res := calculatePortfolio(plants, [][]float64{{1, 0}, {0, 1}}, sigmaBand, Normal{}, pricing); res.Kinds != nil {
+		t.Errorf("single-type portfolio has kinds %+v", res.Kinds)
+	}
+}
+
+func TestParseCorrelations(t *testing.T) {
+	tests := []struct {
+		matrix  string
+		n       int
+		uniform float64
+		wantErr bool
+	}{
+		{"", 2, 0.3, false},
+		{"", 3, 1, false},
+		{"", 2, -1.5, true},
+		{"", 2, math.NaN(), true},
+		{"", 2, math.Inf(1), true},
+		{"1 0.5\n0.5 1", 2, 0, false},
+		{"1 1\n1 1", 2, 0, false},                   // Perfectly correlated: singular but valid
+		{"1 0.5\n0.4 1", 2, 0, true},                // Not symmetric
+		{"1 NaN\nNaN 1", 2, 0, true},                // Not a number
+		{"1 0 0.9\n0 1 0.9\n0.9 0.9 1", 3, 0, true}, // Not positive semidefinite
+	}
+	for _, tt := range tests {
+		if _, err := parseCorrelations(tt.matrix, tt.n, tt.uniform); (err != nil) != tt.wantErr {
+			t.Errorf("%q, ρ = %v: error %v", tt.matrix, tt.uniform, err)
+		}
+	}
+}
+
+func TestPortfolioRejectsNaNCorrelation(t *testing.T) {
+	form := url.Values{
+		"plants":      {"СЕС-1; solar; 5; 1\nВЕС-1; wind; 5; 1"},
+		"correlation": {"NaN"},
+		"energyCost":  {"7"},
+	}
+	w := httptest.NewRecorder()
+	portfolio(w, postForm("/portfolio", form))
+	if w.Code != 400 {
+		t.Errorf("correlation=NaN: status %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Prices for energy sold inside the band and for imbalances on either side of it
+type Pricing struct {
+	EnergyCost        float64 // Price of energy sold without penalty
+	SurplusPrice      float64 // Imbalance price for output above the band (actual > forecast)
+	DeficitPrice      float64 // Imbalance price for output below the band (actual < forecast)
+	SurplusMultiplier float64 // Penalty multiplier applied to the surplus price
+	DeficitMultiplier float64 // Penalty multiplier applied to the deficit price
+}
+
+// Penalty per MWh of surplus and deficit imbalance
+func (p Pricing) SurplusRate() float64 { return p.SurplusPrice * p.SurplusMultiplier }
+func (p Pricing) DeficitRate() float64 { return p.DeficitPrice * p.DeficitMultiplier }
+
+// Whether the tariffs differ from the original symmetric model (penalty = energyCost)
+func (p Pricing) Asymmetric() bool {
+	return p.SurplusRate() != p.EnergyCost || p.DeficitRate() != p.EnergyCost
+}
+
+// Parse the prices; empty imbalance prices default to energyCost and empty multipliers to 1
+func parsePricing(r *http.Request) (Pricing, error) {
+	var p Pricing
+	var err error
+	p.EnergyCost, err = strconv.ParseFloat(r.FormValue("energyCost"), 64)
+	if err != nil || !finite(p.EnergyCost) {
+		return p, fmt.Errorf("Invalid input for energyCost")
+	}
+
+	optional := func(name string, fallback float64) (float64, error) {
+		v := r.FormValue(name)
+		if v == "" {
+			return fallback, nil
+		}
+		x, err := strconv.ParseFloat(v, 64)
+		if err != nil || x < 0 || !finite(x) {
+			return 0, fmt.Errorf("Invalid input for %s", name)
+		}
+		return x, nil
+	}
+	if p.SurplusPrice, err = optional("surplusPrice", p.EnergyCost); err != nil {
+		return p, err
+	}
+	if p.DeficitPrice, err = optional("deficitPrice", p.EnergyCost); err != nil {
+		return p, err
+	}
+	if p.SurplusMultiplier, err = optional("surplusMultiplier", 1); err != nil {
+		return p, err
+	}
+	if p.DeficitMultiplier, err = optional("deficitMultiplier", 1); err != nil {
+		return p, err
+	}
+	return p, nil
+}
+
+// Scale the energy and imbalance prices by a factor (imbalance prices follow the market price)
+func (p Pricing) Scale(factor float64) Pricing {
+	p.EnergyCost *= factor
+	p.SurplusPrice *= factor
+	p.DeficitPrice *= factor
+	return p
+}
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestParsePricingDefaults(t *testing.T) {
+	tests := []struct {
+		form    url.Values
+		want    Pricing
+		wantErr bool
+	}{
+		{url.Values{"energyCost": {"7"}}, Pricing{7, 7, 7, 1, 1}, false},
+		{url.Values{"energyCost": {"7"}, "surplusPrice": {"5"}, "deficitMultiplier": {"1.5"}}, Pricing{7, 5, 7, 1, 1.5}, false},
+		{url.Values{"energyCost": {"7"}, "surplusMultiplier": {"-1"}}, Pricing{}, true},
+		{url.Values{"energyCost": {"NaN"}}, Pricing{}, true},
+		{url.Values{"energyCost": {"7"}, "surplusPrice": {"nan"}}, Pricing{}, true},
+		{url.Values{"energyCost": {"7"}, "deficitPrice": {"+Inf"}}, Pricing{}, true},
+		{url.Values{"energyCost": {"7"}, "deficitMultiplier": {"NaN"}}, Pricing{}, true},
+		{url.Values{}, Pricing{}, true},
+	}
+	for _, tt := range tests {
+		p, err := parsePricing(postForm("/calculate", tt.form))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: error %v", tt.form, err)
+			continue
+		}
+		if !tt.wantErr && p != tt.want {
+			t.Errorf("%v: got %+v, want %+v", tt.form, p, tt.want)
+		}
+	}
+}
+
+func TestSettleProfileAsymmetricPricing(t *testing.T) {
+	// Flat 5 MW, σ1 = 1, σ2 = 0.25, ±5 % band: P(outside) per side is Φ(-0.25) before, Φ(-1) after
+	p := Profile{Forecast: repeat(5, 24), StdDevBefore: repeat(1, 24), StdDevAfter: repeat(0.25, 24), Bias: repeat(0, 24)}
+	pricing := Pricing{EnergyCost: 7, SurplusPrice: 7, DeficitPrice: 7, SurplusMultiplier: 2, DeficitMultiplier: 1}
+	_, before, after := settleProfile(p, Tolerance{Mode: TolerancePercent, Lower: 5, Upper: 5}, Normal{}, pricing)
+
+	approx(t, "W1", before.InBand, 23.689518, 1e-5)
+	approx(t, "surplus before", before.Surplus, 48.155241, 1e-5)
+	approx(t, "surplus penalty before", before.SurplusPenalty, 48.155241*14, 1e-4)
+	approx(t, "deficit penalty before", before.DeficitPenalty, 48.155241*7, 1e-4)
+	approx(t, "final profit before", before.FinalProfit, -845.433432, 1e-4)
+	approx(t, "W3", after.InBand, 81.922739, 1e-5)
+	approx(t, "final profit after", after.FinalProfit, 173.647933, 1e-4)
+}
+
+func TestCalculateHandler(t *testing.T) {
+	form := url.Values{
+		"dailyPower":        {"5"},
+		"currentStdDev":     {"1"},
+		"futureStdDev":      {"0.25"},
+		"energyCost":        {"7"},
+		"surplusMultiplier": {"2"},
+		"toleranceMode":     {"percent"},
+		"toleranceLower":    {"5"},
+	}
+	w := httptest.NewRecorder()
+	calculate(w, postForm("/calculate", form))
+	if w.Code != 200 {
+		t.Fatalf("status %d: %s", w.Code, w.Body)
+	}
+	for _, want := range []string{"-845.43", "173.65"} {
+		if !strings.Contains(w.Body.String(), want) {
+			t.Errorf("result page lacks %s", want)
+		}
+	}
+
+	form.Set("currentStdDev", "-1")
+	w = httptest.NewRecorder()
+	calculate(w, postForm("/calculate", form))
+	if w.Code != 400 {
+		t.Errorf("negative σ: status %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,31 @@
+package main
+
+import "math"
+
+// Cumulative normal distribution, exact via the error function
+func normalCDF(p, Pc, stdDev float64) float64 {
+	return 0.5 * (1 + math.Erf((p-Pc)/(stdDev*math.Sqrt2)))
+}
+
+// Probabilities that the actual power, distributed around Pc with the given error shape,
+// falls below P_lower and above P_upper
+func tailProbabilities(dist ErrorDistribution, Pc, stdDev, P_lower, P_upper float64) (float64, float64) {
+	if stdDev <= 0 {
+		// Perfect forecast: the actual power equals Pc
+		below, above := 0.0, 0.0
+		if Pc < P_lower {
+			below = 1
+		}
+		if Pc > P_upper {
+			above = 1
+		}
+		return below, above
+	}
+	return dist.CDF(P_lower, Pc, stdDev), 1 - dist.CDF(P_upper, Pc, stdDev)
+}
+
+// Probability that the actual power falls within [P_lower, P_upper]
+func bandProbability(dist ErrorDistribution, Pc, stdDev, P_lower, P_upper float64) float64 {
+	below, above := tailProbabilities(dist, Pc, stdDev, P_lower, P_upper)
+	return 1 - below - above
+}
@@ -0,0 +1,96 @@
+package main
+
+import (
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func approx(t *testing.T, name string, got, want, tol float64) {
+	t.Helper()
+	if math.Abs(got-want) > tol {
+		t.Errorf("%s = %.6f, want %.6f", name, got, want)
+	}
+}
+
+// postForm builds a POST request with the given form fields
+func postForm(target string, form url.Values) *http.Request {
+	r := httptest.NewRequest(http.MethodPost, target, strings.NewReader(form.Encode()))
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return r
+}
+
+func TestNormalCDF(t *testing.T) {
+	tests := []struct {
+		p, mean, stdDev, want float64
+	}{
+		{0, 0, 1, 0.5},
+		{1, 0, 1, 0.841345},
+		{-1, 0, 1, 0.158655},
+		{1.96, 0, 1, 0.975002},
+		{5.25, 5, 1, 0.598706},
+		{4.75, 5, 0.25, 0.158655},
+	}
+	for _, tt := range tests {
+		approx(t, "Φ", normalCDF(tt.p, tt.mean, tt.stdDev), tt.want, 1e-6)
+	}
+}
+
+func TestTailProbabilities(t *testing.T) {
+	tests := []struct {
+		name                       string
+		Pc, stdDev, lower, upper   float64
+		wantBelow, wantAbove, band float64
+	}{
+		{"σ 1, band ±5 %", 5, 1, 4.75, 5.25, 0.401294, 0.401294, 0.197413},
+		{"σ 0.25, band ±5 %", 5, 0.25, 4.75, 5.25, 0.158655, 0.158655, 0.682689},
+		{"perfect forecast in band", 5, 0, 4.75, 5.25, 0, 0, 1},
+		{"perfect forecast below band", 4, 0, 4.75, 5.25, 1, 0, 0},
+	}
+	for _, tt := range tests {
+		below, above := tailProbabilities(Normal{}, tt.Pc, tt.stdDev, tt.lower, tt.upper)
+		approx(t, tt.name+" below", below, tt.wantBelow, 1e-6)
+		approx(t, tt.name+" above", above, tt.wantAbove, 1e-6)
+		approx(t, tt.name+" band", bandProbability(Normal{}, tt.Pc, tt.stdDev, tt.lower, tt.upper), tt.band, 1e-6)
+	}
+}
+
+func TestParseForecastStdDev(t *testing.T) {
+	tests := []struct {
+		current, future string
+		wantErr         string
+	}{
+		{"1", "0.25", ""},
+		{"0", "0", ""},
+		{"-1", "0.25", "currentStdDev"},
+		{"NaN", "0.25", "currentStdDev"},
+		{"1", "-0.25", "futureStdDev"},
+		{"1", "nan", "futureStdDev"},
+		{"1", "+Inf", "futureStdDev"},
+	}
+	for _, tt := range tests {
+		r := postForm("/calculate", url.Values{
+			"dailyPower":    {"5"},
+			"currentStdDev": {tt.current},
+			"futureStdDev":  {tt.future},
+		})
+		_, err := parseForecast(r)
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("σ %s/%s: unexpected error %v", tt.current, tt.future, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("σ %s/%s: error %v, want one about %s", tt.current, tt.future, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseProfileRejectsNaN(t *testing.T) {
+	for _, s := range []string{"1 NaN 2", "1 Inf", "1 -2"} {
+		if _, err := parseProfile(s); err == nil {
+			t.Errorf("parseProfile(%q) accepted", s)
+		}
+	}
+}
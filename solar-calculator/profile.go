@@ -0,0 +1,180 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Settlement of one scenario (before or after improvement) over an interval or the whole day
+type Settlement struct {
+	InBand      float64 // Energy sold without penalty (W1/W3), MWh
+	Imbalance   float64 // Energy outside the tolerance band (W2/W4), MWh
+	Profit      float64
+	Penalty     float64
+	FinalProfit float64
+	Share       float64 // Share of energy inside the band
+
+	// Imbalance split by direction
+	Surplus, Deficit               float64 // Energy above / below the band, MWh
+	SurplusPenalty, DeficitPenalty float64
+}
+
+// Add accumulates another settlement; Share becomes energy-weighted
+func (s *Settlement) Add(o Settlement) {
+	s.InBand += o.InBand
+	s.Imbalance += o.Imbalance
+	s.Profit += o.Profit
+	s.Penalty += o.Penalty
+	s.FinalProfit += o.FinalProfit
+	s.Surplus += o.Surplus
+	s.Deficit += o.Deficit
+	s.SurplusPenalty += o.SurplusPenalty
+	s.DeficitPenalty += o.DeficitPenalty
+	if total := s.InBand + s.Imbalance; total > 0 {
+		s.Share = s.InBand / total
+	}
+}
+
+// Settle one interval: the share of energy inside [lower, upper] is paid, the surplus above
+// and the deficit below the band are penalised at their own rates.
+// The actual output is distributed around forecast + bias with the error shape dist.
+func settle(dist ErrorDistribution, forecast, bias, stdDev, lower, upper, hours float64, pricing Pricing) Settlement {
+	below, above := tailProbabilities(dist, forecast+bias, stdDev, lower, upper)
+	energy := forecast * hours
+	s := Settlement{
+		InBand:  energy * (1 - below - above),
+		Surplus: energy * above,
+		Deficit: energy * below,
+	}
+	s.Price(pricing)
+	return s
+}
+
+// Price fills the imbalance total, profit and penalties from the in-band, surplus and deficit energy
+func (s *Settlement) Price(pricing Pricing) {
+	s.Imbalance = s.Surplus + s.Deficit
+	s.Profit = s.InBand * pricing.EnergyCost
+	s.SurplusPenalty = s.Surplus * pricing.SurplusRate()
+	s.DeficitPenalty = s.Deficit * pricing.DeficitRate()
+	s.Penalty = s.SurplusPenalty + s.DeficitPenalty
+	s.FinalProfit = s.Profit - s.Penalty
+	if total := s.InBand + s.Imbalance; total > 0 {
+		s.Share = s.InBand / total
+	}
+}
+
+// Forecast profile for one day; the interval length is 24 h / len(Forecast)
+type Profile struct {
+	Forecast     []float64 // Forecast power per interval, MW
+	StdDevBefore []float64 // Forecast error σ1 per interval
+	StdDevAfter  []float64 // Forecast error σ2 per interval (after improvement)
+	Bias         []float64 // Mean error of the current forecast per interval; the improved one is unbiased
+}
+
+// Interval length in hours
+func (p Profile) IntervalHours() float64 {
+	return 24 / float64(len(p.Forecast))
+}
+
+// Result for one interval of the profile
+type IntervalResult struct {
+	Start                     string // "HH:MM"
+	Forecast                  float64
+	StdDevBefore, StdDevAfter float64
+	BandLower, BandUpper      float64
+	Before, After             Settlement
+}
+
+// Settle every interval of the profile and sum up the day
+func settleProfile(p Profile, tolerance Tolerance, dist ErrorDistribution, pricing Pricing) ([]IntervalResult, Settlement, Settlement) {
+	hours := p.IntervalHours()
+	intervals := make([]IntervalResult, len(p.Forecast))
+	var before, after Settlement
+	for i, forecast := range p.Forecast {
+		lower, upper := tolerance.Band(forecast, p.StdDevAfter[i])
+		minutes := int(float64(i) * hours * 60)
+		intervals[i] = IntervalResult{
+			Start:        fmt.Sprintf("%02d:%02d", minutes/60, minutes%60),
+			Forecast:     forecast,
+			StdDevBefore: p.StdDevBefore[i],
+			StdDevAfter:  p.StdDevAfter[i],
+			BandLower:    lower,
+			BandUpper:    upper,
+			Before:       settle(dist, forecast, p.Bias[i], p.StdDevBefore[i], lower, upper, hours, pricing),
+			After:        settle(dist, forecast, 0, p.StdDevAfter[i], lower, upper, hours, pricing),
+		}
+		before.Add(intervals[i].Before)
+		after.Add(intervals[i].After)
+	}
+	return intervals, before, after
+}
+
+// Parse a list of numbers separated by commas, semicolons or whitespace
+func parseNumbers(s string) ([]float64, error) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ';' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+	values := make([]float64, len(fields))
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil || !finite(v) {
+			return nil, fmt.Errorf("value %d (%q) is not a number", i+1, f)
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+// Parse a list of non-negative numbers (power or σ)
+func parseProfile(s string) ([]float64, error) {
+	values, err := parseNumbers(s)
+	if err != nil {
+		return nil, err
+	}
+	for i, v := range values {
+		if v < 0 {
+			return nil, fmt.Errorf("value %d is negative", i+1)
+		}
+	}
+	return values, nil
+}
+
+// Parse a profile of n non-negative values; a single value (or the fallback when empty) is used for every interval
+func parseProfileOrScalar(s string, n int, fallback float64) ([]float64, error) {
+	values, err := parseProfile(s)
+	if err != nil {
+		return nil, err
+	}
+	return broadcast(values, n, fallback)
+}
+
+// Same as parseProfileOrScalar, but values may be negative (bias)
+func parseSignedProfileOrScalar(s string, n int, fallback float64) ([]float64, error) {
+	values, err := parseNumbers(s)
+	if err != nil {
+		return nil, err
+	}
+	return broadcast(values, n, fallback)
+}
+
+// Expand zero or one value to n intervals
+func broadcast(values []float64, n int, fallback float64) ([]float64, error) {
+	switch len(values) {
+	case 0:
+		return repeat(fallback, n), nil
+	case 1:
+		return repeat(values[0], n), nil
+	case n:
+		return values, nil
+	}
+	return nil, fmt.Errorf("expected 1 or %d values, got %d", n, len(values))
+}
+
+func repeat(v float64, n int) []float64 {
+	values := make([]float64, n)
+	for i := range values {
+		values[i] = v
+	}
+	return values
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestSettleProfile(t *testing.T) {
+	// Four 6-hour intervals with their own σ and a biased current forecast; the band is ±σ2
+	p := Profile{
+		Forecast:     []float64{0, 4, 6, 2},
+		StdDevBefore: []float64{0.5, 1, 1.5, 0.5},
+		StdDevAfter:  []float64{0.2, 0.4, 0.5, 0.2},
+		Bias:         []float64{0, 0.5, -0.5, 0},
+	}
+	pricing := Pricing{EnergyCost: 7, SurplusPrice: 7, DeficitPrice: 7, SurplusMultiplier: 1, DeficitMultiplier: 1}
+	intervals, before, after := settleProfile(p, Tolerance{Mode: ToleranceSigma}, Normal{}, pricing)
+
+	tests := []struct {
+		start                string
+		bandLower, bandUpper float64
+	}{
+		{"00:00", -0.2, 0.2},
+		{"06:00", 3.6, 4.4},
+		{"12:00", 5.5, 6.5},
+		{"18:00", 1.8, 2.2},
+	}
+	for i, tt := range tests {
+		if intervals[i].Start != tt.start {
+			t.Errorf("interval %d starts at %s, want %s", i, intervals[i].Start, tt.start)
+		}
+		approx(t, tt.start+" lower", intervals[i].BandLower, tt.bandLower, 1e-9)
+		approx(t, tt.start+" upper", intervals[i].BandUpper, tt.bandUpper, 1e-9)
+	}
+
+	approx(t, "W1", before.InBand, 19.267079, 1e-5)
+	approx(t, "W2", before.Imbalance, 52.732921, 1e-5)
+	approx(t, "surplus before", before.Surplus, 26.180539, 1e-5)
+	approx(t, "final profit before", before.FinalProfit, -234.260889, 1e-4)
+	approx(t, "share before", before.Share, 0.267598, 1e-6)
+	approx(t, "W3", after.InBand, 49.153643, 1e-5)
+	approx(t, "W4", after.Imbalance, 22.846357, 1e-5)
+	approx(t, "final profit after", after.FinalProfit, 184.151008, 1e-4)
+	// The unbiased improved forecast is inside ±σ2 with probability 2Φ(1) - 1 in every interval
+	approx(t, "share after", after.Share, 0.682689, 1e-6)
+}
+
+func TestIntervalStart(t *testing.T) {
+	p := Profile{Forecast: repeat(1, 96), StdDevBefore: repeat(1, 96), StdDevAfter: repeat(1, 96), Bias: repeat(0, 96)}
+	intervals, before, _ := settleProfile(p, Tolerance{Mode: ToleranceSigma}, Normal{}, Pricing{EnergyCost: 1})
+	for i, want := range map[int]string{0: "00:00", 1: "00:15", 50: "12:30", 95: "23:45"} {
+		if intervals[i].Start != want {
+			t.Errorf("interval %d starts at %s, want %s", i, intervals[i].Start, want)
+		}
+	}
+	// 15-minute intervals still add up to 24 MWh for a flat 1 MW forecast
+	approx(t, "energy", before.InBand+before.Imbalance, 24, 1e-9)
+}
+
+func TestParseForecastProfile(t *testing.T) {
+	hourly := strings.Repeat("1 ", 23) + "2"
+	tests := []struct {
+		name    string
+		form    url.Values
+		n       int
+		wantErr string
+	}{
+		{"flat", url.Values{"dailyPower": {"5"}}, 24, ""},
+		{"hourly", url.Values{"forecastProfile": {hourly}}, 24, ""},
+		{"15-minute", url.Values{"forecastProfile": {strings.Repeat("1;", 96)}}, 96, ""},
+		{"hourly σ", url.Values{"forecastProfile": {hourly}, "currentStdDevProfile": {hourly}}, 24, ""},
+		{"negative bias", url.Values{"dailyPower": {"5"}, "currentBiasProfile": {"-0.5"}}, 24, ""},
+		{"power not a number", url.Values{"dailyPower": {"NaN"}}, 0, "dailyPower"},
+		{"wrong length", url.Values{"forecastProfile": {"1 2 3"}}, 0, "forecastProfile"},
+		{"negative power", url.Values{"forecastProfile": {"-1" + strings.Repeat(",1", 23)}}, 0, "forecastProfile"},
+		{"σ length", url.Values{"dailyPower": {"5"}, "futureStdDevProfile": {"1 2"}}, 0, "futureStdDevProfile"},
+		{"bias not a number", url.Values{"dailyPower": {"5"}, "currentBiasProfile": {"x"}}, 0, "currentBiasProfile"},
+	}
+	for _, tt := range tests {
+		tt.form.Set("currentStdDev", "1")
+		tt.form.Set("futureStdDev", "0.25")
+		p, err := parseForecast(postForm("/calculate", tt.form))
+		switch {
+		case tt.wantErr == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.name, err)
+		case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+			t.Errorf("%s: error %v, want one about %s", tt.name, err, tt.wantErr)
+		case tt.wantErr == "":
+			for name, values := range map[string][]float64{"σ1": p.StdDevBefore, "σ2": p.StdDevAfter, "bias": p.Bias} {
+				if len(values) != tt.n {
+					t.Errorf("%s: %d %s values, want %d", tt.name, len(values), name, tt.n)
+				}
+			}
+		}
+	}
+}
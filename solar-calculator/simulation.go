@@ -0,0 +1,167 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"net/http"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// Monte Carlo settings
+type Simulation struct {
+	Days   int
+	Hourly bool // Independent error per interval; otherwise one error per day for all intervals
+	Seed   int64
+}
+
+// Upper bound on simulated days per request
+const maxSimulationDays = 100000
+
+// Days per month for the loss-making month probability
+const daysPerMonth = 30
+
+// Distribution of simulated daily revenue
+type RevenueStats struct {
+	Mean, StdDev           float64
+	P5, P25, P50, P75, P95 float64
+	VaR                    float64 // 95 % value-at-risk: shortfall of the 5th percentile below the mean
+	Months                 int     // Number of simulated 30-day months
+	LossMonthProbability   float64 // Share of months with negative total revenue
+	LossDayProbability     float64 // Share of days with negative revenue
+	WorstDay, BestDay      float64
+}
+
+// Result of the simulation before and after the forecast improvement
+type SimulationResult struct {
+	Simulation
+	Before, After RevenueStats
+}
+
+// Parse the simulation fields; ok is false when no simulation was requested
+func parseSimulation(r *http.Request) (Simulation, bool, error) {
+	v := r.FormValue("simulationDays")
+	if v == "" {
+		return Simulation{}, false, nil
+	}
+	days, err := strconv.Atoi(v)
+	if err != nil || days < 1 || days > maxSimulationDays {
+		return Simulation{}, false, fmt.Errorf("Invalid input for simulationDays: expected 1 to %d days", maxSimulationDays)
+	}
+	sim := Simulation{Days: days, Hourly: r.FormValue("simulationMode") == "hourly"}
+	if v := r.FormValue("seed"); v != "" {
+		sim.Seed, err = strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return Simulation{}, false, fmt.Errorf("Invalid input for seed")
+		}
+	} else {
+		// Random seed, reported back so the run can be reproduced
+		sim.Seed = time.Now().UnixNano() % 1000000000
+	}
+	return sim, true, nil
+}
+
+// Simulate daily revenue: every interval is paid in full if the sampled actual power is inside
+// the band and penalised at the surplus/deficit rate otherwise, so the mean matches the expected value.
+// The actual power is sampled by inverse transform: a uniform u below P(actual < lower) is a deficit,
+// above 1 - P(actual > upper) a surplus, which works for every error distribution.
+func simulate(p Profile, tolerance Tolerance, dist ErrorDistribution, pricing Pricing, sim Simulation) SimulationResult {
+	rng := rand.New(rand.NewSource(sim.Seed))
+	hours := p.IntervalHours()
+
+	// Tail probabilities do not change from day to day
+	n := len(p.Forecast)
+	belowBefore, aboveBefore := make([]float64, n), make([]float64, n)
+	belowAfter, aboveAfter := make([]float64, n), make([]float64, n)
+	for i, forecast := range p.Forecast {
+		lower, upper := tolerance.Band(forecast, p.StdDevAfter[i])
+		belowBefore[i], aboveBefore[i] = tailProbabilities(dist, forecast+p.Bias[i], p.StdDevBefore[i], lower, upper)
+		belowAfter[i], aboveAfter[i] = tailProbabilities(dist, forecast, p.StdDevAfter[i], lower, upper)
+	}
+
+	day := func(below, above []float64) float64 {
+		revenue := 0.0
+		u := rng.Float64()
+		for i, forecast := range p.Forecast {
+			if sim.Hourly {
+				u = rng.Float64()
+			}
+			energy := forecast * hours
+			switch {
+			case u < below[i]:
+				revenue -= energy * pricing.DeficitRate()
+			case u >= 1-above[i]:
+				revenue -= energy * pricing.SurplusRate()
+			default:
+				revenue += energy * pricing.EnergyCost
+			}
+		}
+		return revenue
+	}
+
+	before := make([]float64, sim.Days)
+	after := make([]float64, sim.Days)
+	for d := range before {
+		before[d] = day(belowBefore, aboveBefore)
+		after[d] = day(belowAfter, aboveAfter)
+	}
+	return SimulationResult{sim, revenueStats(before), revenueStats(after)}
+}
+
+// Summary statistics of simulated daily revenues
+func revenueStats(days []float64) RevenueStats {
+	n := float64(len(days))
+	var s RevenueStats
+	for _, v := range days {
+		s.Mean += v
+	}
+	s.Mean /= n
+	for _, v := range days {
+		s.StdDev += (v - s.Mean) * (v - s.Mean)
+		if v < 0 {
+			s.LossDayProbability++
+		}
+	}
+	if len(days) > 1 {
+		s.StdDev = math.Sqrt(s.StdDev / (n - 1))
+	}
+	s.LossDayProbability /= n
+
+	// Consecutive 30-day blocks
+	for m := 0; m+daysPerMonth <= len(days); m += daysPerMonth {
+		total := 0.0
+		for _, v := range days[m : m+daysPerMonth] {
+			total += v
+		}
+		s.Months++
+		if total < 0 {
+			s.LossMonthProbability++
+		}
+	}
+	if s.Months > 0 {
+		s.LossMonthProbability /= float64(s.Months)
+	}
+
+	sorted := append([]float64(nil), days...)
+	sort.Float64s(sorted)
+	s.WorstDay, s.BestDay = sorted[0], sorted[len(sorted)-1]
+	s.P5 = percentile(sorted, 5)
+	s.P25 = percentile(sorted, 25)
+	s.P50 = percentile(sorted, 50)
+	s.P75 = percentile(sorted, 75)
+	s.P95 = percentile(sorted, 95)
+	s.VaR = s.Mean - s.P5
+	return s
+}
+
+// Percentile of sorted values with linear interpolation
+func percentile(sorted []float64, pct float64) float64 {
+	pos := pct / 100 * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	return sorted[i] + (pos-float64(i))*(sorted[i+1]-sorted[i])
+}
@@ -0,0 +1,114 @@
+package main
+
+import (
+	"math"
+	"net/url"
+	"testing"
+)
+
+func TestRevenueStats(t *testing.T) {
+	// Two 30-day months: -20 … 9 (a loss) and 10 … 39
+	days := make([]float64, 60)
+	for i := range days {
+		days[i] = float64(i - 20)
+	}
+	// The order of the days must not matter for the percentiles
+	days[0], days[59] = days[59], days[0]
+	s := revenueStats(days)
+
+	approx(t, "mean", s.Mean, 9.5, 1e-9)
+	approx(t, "σ", s.StdDev, 17.464249, 1e-6)
+	approx(t, "P5", s.P5, -17.05, 1e-9)
+	approx(t, "P25", s.P25, -5.25, 1e-9)
+	approx(t, "P50", s.P50, 9.5, 1e-9)
+	approx(t, "P75", s.P75, 24.25, 1e-9)
+	approx(t, "P95", s.P95, 36.05, 1e-9)
+	approx(t, "VaR", s.VaR, 26.55, 1e-9)
+	approx(t, "loss days", s.LossDayProbability, 20.0/60, 1e-9)
+	approx(t, "worst day", s.WorstDay, -20, 0)
+	approx(t, "best day", s.BestDay, 39, 0)
+	if s.Months != 2 {
+		t.Errorf("months = %d, want 2", s.Months)
+	}
+	// The swap moved 39 into the first month, which still totals -165 + 39 - (-20) < 0
+	approx(t, "loss months", s.LossMonthProbability, 0.5, 1e-9)
+}
+
+func TestPercentile(t *testing.T) {
+	tests := []struct {
+		sorted    []float64
+		pct, want float64
+	}{
+		{[]float64{1, 2, 3, 4}, 50, 2.5},
+		{[]float64{1, 2, 3, 4}, 0, 1},
+		{[]float64{1, 2, 3, 4}, 100, 4},
+		{[]float64{10}, 95, 10},
+	}
+	for _, tt := range tests {
+		approx(t, "percentile", percentile(tt.sorted, tt.pct), tt.want, 1e-9)
+	}
+}
+
+func TestSimulateMatchesExpectedValue(t *testing.T) {
+	// Same day as TestSettleProfileAsymmetricPricing: -845.433432 before, 173.647933 after
+	p := Profile{Forecast: repeat(5, 24), StdDevBefore: repeat(1, 24), StdDevAfter: repeat(0.25, 24), Bias: repeat(0, 24)}
+	pricing := Pricing{EnergyCost: 7, SurplusPrice: 7, DeficitPrice: 7, SurplusMultiplier: 2, DeficitMultiplier: 1}
+	tolerance := Tolerance{Mode: TolerancePercent, Lower: 5, Upper: 5}
+
+	for _, hourly := range []bool{true, false} {
+		sim := Simulation{Days: 20000, Hourly: hourly, Seed: 7}
+		res := simulate(p, tolerance, Normal{}, pricing, sim)
+		for _, c := range []struct {
+			name  string
+			stats RevenueStats
+			want  float64
+		}{
+			{"before", res.Before, -845.433432},
+			{"after", res.After, 173.647933},
+		} {
+			// Within four standard errors of the expected daily profit
+			if se := c.stats.StdDev / math.Sqrt(float64(sim.Days)); math.Abs(c.stats.Mean-c.want) > 4*se {
+				t.Errorf("hourly %v, %s: mean %.3f, want %.3f ± %.3f", hourly, c.name, c.stats.Mean, c.want, 4*se)
+			}
+		}
+
+		// A fixed seed reproduces the run
+		if again := simulate(p, tolerance, Normal{}, pricing, sim); again != res {
+			t.Errorf("hourly %v: seed %d is not reproducible", hourly, sim.Seed)
+		}
+	}
+
+	// One error per day: every interval lands on the same side, so the day is all-or-nothing
+	res := simulate(p, tolerance, Normal{}, pricing, Simulation{Days: 1000, Seed: 1})
+	for _, v := range []float64{res.Before.WorstDay, res.Before.BestDay} {
+		if v != -120*14 && v != -120*7 && v != 120*7 {
+			t.Errorf("daily mode revenue %v is not a whole-day outcome", v)
+		}
+	}
+}
+
+func TestParseSimulation(t *testing.T) {
+	tests := []struct {
+		form    url.Values
+		want    Simulation
+		ok      bool
+		wantErr bool
+	}{
+		{url.Values{}, Simulation{}, false, false},
+		{url.Values{"simulationDays": {"365"}, "seed": {"42"}}, Simulation{Days: 365, Seed: 42}, true, false},
+		{url.Values{"simulationDays": {"10"}, "simulationMode": {"hourly"}, "seed": {"1"}}, Simulation{Days: 10, Hourly: true, Seed: 1}, true, false},
+		{url.Values{"simulationDays": {"0"}}, Simulation{}, false, true},
+		{url.Values{"simulationDays": {"100001"}}, Simulation{}, false, true},
+		{url.Values{"simulationDays": {"10"}, "seed": {"x"}}, Simulation{}, false, true},
+	}
+	for _, tt := range tests {
+		sim, ok, err := parseSimulation(postForm("/calculate", tt.form))
+		if (err != nil) != tt.wantErr || ok != tt.ok {
+			t.Errorf("%v: ok %v, error %v", tt.form, ok, err)
+			continue
+		}
+		if ok && sim != tt.want {
+			t.Errorf("%v: got %+v, want %+v", tt.form, sim, tt.want)
+		}
+	}
+}
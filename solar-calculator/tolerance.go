@@ -0,0 +1,72 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+)
+
+// Tolerance band modes
+const (
+	ToleranceSigma    = "sigma"   // ±σ2 around the forecast (original behaviour)
+	ToleranceAbsolute = "abs"     // Fixed MW below/above the forecast
+	TolerancePercent  = "percent" // Percent of the forecast below/above it
+)
+
+// Tolerance band around the forecast inside which energy is sold without penalty
+type Tolerance struct {
+	Mode  string
+	Lower float64 // Allowed deviation below the forecast (MW or %)
+	Upper float64 // Allowed deviation above the forecast (MW or %)
+}
+
+// Band returns the absolute [lower, upper] power limits for a forecast
+func (t Tolerance) Band(forecast, futureStdDev float64) (float64, float64) {
+	switch t.Mode {
+	case ToleranceAbsolute:
+		return forecast - t.Lower, forecast + t.Upper
+	case TolerancePercent:
+		return forecast * (1 - t.Lower/100), forecast * (1 + t.Upper/100)
+	default:
+		return forecast - futureStdDev, forecast + futureStdDev
+	}
+}
+
+// String describes the band for the results page
+func (t Tolerance) String() string {
+	switch t.Mode {
+	case ToleranceAbsolute:
+		return fmt.Sprintf("-%.3g / +%.3g МВт", t.Lower, t.Upper)
+	case TolerancePercent:
+		return fmt.Sprintf("-%.3g / +%.3g %%", t.Lower, t.Upper)
+	default:
+		return "±σ2"
+	}
+}
+
+// Parse the tolerance fields; an empty upper limit makes the band symmetric
+func parseTolerance(r *http.Request) (Tolerance, error) {
+	t := Tolerance{Mode: r.FormValue("toleranceMode")}
+	switch t.Mode {
+	case "", ToleranceSigma:
+		t.Mode = ToleranceSigma
+		return t, nil
+	case ToleranceAbsolute, TolerancePercent:
+	default:
+		return t, fmt.Errorf("unknown tolerance mode %q", t.Mode)
+	}
+
+	var err error
+	t.Lower, err = strconv.ParseFloat(r.FormValue("toleranceLower"), 64)
+	if err != nil || t.Lower < 0 || !finite(t.Lower) {
+		return t, fmt.Errorf("invalid input for toleranceLower")
+	}
+	t.Upper = t.Lower
+	if v := r.FormValue("toleranceUpper"); v != "" {
+		t.Upper, err = strconv.ParseFloat(v, 64)
+		if err != nil || t.Upper < 0 || !finite(t.Upper) {
+			return t, fmt.Errorf("invalid input for toleranceUpper")
+		}
+	}
+	return t, nil
+}
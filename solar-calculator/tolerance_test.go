@@ -0,0 +1,50 @@
+package main
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+func TestParseTolerance(t *testing.T) {
+	tests := []struct {
+		form    url.Values
+		want    Tolerance
+		wantErr bool
+	}{
+		{url.Values{}, Tolerance{Mode: ToleranceSigma}, false},
+		{url.Values{"toleranceMode": {"percent"}, "toleranceLower": {"5"}}, Tolerance{TolerancePercent, 5, 5}, false},
+		{url.Values{"toleranceMode": {"abs"}, "toleranceLower": {"0.5"}, "toleranceUpper": {"1"}}, Tolerance{ToleranceAbsolute, 0.5, 1}, false},
+		{url.Values{"toleranceMode": {"percent"}, "toleranceLower": {"-5"}}, Tolerance{}, true},
+		{url.Values{"toleranceMode": {"percent"}, "toleranceLower": {"NaN"}}, Tolerance{}, true},
+		{url.Values{"toleranceMode": {"abs"}, "toleranceLower": {"Inf"}}, Tolerance{}, true},
+		{url.Values{"toleranceMode": {"abs"}, "toleranceLower": {"1"}, "toleranceUpper": {"nan"}}, Tolerance{}, true},
+		{url.Values{"toleranceMode": {"band"}}, Tolerance{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseTolerance(postForm("/calculate", tt.form))
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%v: error %v", tt.form, err)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("%v: got %+v, want %+v", tt.form, got, tt.want)
+		}
+	}
+}
+
+func TestCalculateRejectsNaNTolerance(t *testing.T) {
+	form := url.Values{
+		"dailyPower":     {"5"},
+		"currentStdDev":  {"1"},
+		"futureStdDev":   {"0.25"},
+		"energyCost":     {"7"},
+		"toleranceMode":  {"percent"},
+		"toleranceLower": {"NaN"},
+	}
+	w := httptest.NewRecorder()
+	calculate(w, postForm("/calculate", form))
+	if w.Code != 400 {
+		t.Errorf("toleranceLower=NaN: status %d, want 400", w.Code)
+	}
+}
@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Wind speed error models
+const (
+	WindNormal  = "normal"  // Actual speed ~ N(forecast, σ), negative speeds count as calm
+	WindWeibull = "weibull" // Actual speed ~ Weibull with the forecast as mean and the given σ
+)
+
+// Number of steps used to discretise the wind speed distribution
+const windSteps = 4000
+
+// Turbine power curve: power in kW at increasing wind speeds in m/s, linear in between.
+// Zero below the first and above the last point (cut-in / cut-out).
+type PowerCurve struct {
+	Speeds []float64
+	Power  []float64
+}
+
+// Generic 3 MW turbine: cut-in 3.5 m/s, rated at 14 m/s, cut-out 25 m/s
+var defaultPowerCurve = PowerCurve{
+	Speeds: []float64{3.5, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 25},
+	Power:  []float64{0, 40, 150, 330, 590, 950, 1400, 1900, 2400, 2750, 2930, 3000, 3000},
+}
+
+// Power at a wind speed, kW
+func (c PowerCurve) At(v float64) float64 {
+	n := len(c.Speeds)
+	if v < c.Speeds[0] || v > c.Speeds[n-1] {
+		return 0
+	}
+	for i := 1; i < n; i++ {
+		if v <= c.Speeds[i] {
+			frac := (v - c.Speeds[i-1]) / (c.Speeds[i] - c.Speeds[i-1])
+			return c.Power[i-1] + frac*(c.Power[i]-c.Power[i-1])
+		}
+	}
+	return c.Power[n-1]
+}
+
+// Rated (maximum) power of the curve, kW
+func (c PowerCurve) Rated() float64 {
+	return maxOf(c.Power)
+}
+
+// Parse a power curve, one "speed power" pair per line
+func parsePowerCurve(s string) (PowerCurve, error) {
+	if strings.TrimSpace(s) == "" {
+		return defaultPowerCurve, nil
+	}
+	var c PowerCurve
+	for i, line := range strings.Split(strings.TrimSpace(s), "\n") {
+		values, err := parseProfile(line)
+		if err != nil || len(values) != 2 {
+			return c, fmt.Errorf("line %d: expected wind speed and power", i+1)
+		}
+		if len(c.Speeds) > 0 && values[0] <= c.Speeds[len(c.Speeds)-1] {
+			return c, fmt.Errorf("line %d: wind speeds must increase", i+1)
+		}
+		c.Speeds = append(c.Speeds, values[0])
+		c.Power = append(c.Power, values[1])
+	}
+	if len(c.Speeds) < 2 {
+		return c, fmt.Errorf("at least two points are required")
+	}
+	return c, nil
+}
+
+// Probability mass at a wind speed
+type speedMass struct {
+	Speed, P float64
+}
+
+// Discretise the actual wind speed around the forecast mean with the given σ.
+// The grid reaches at least the cut-out speed; the mass beyond it is placed above the grid,
+// where the turbines are stopped, rather than at the last grid speed.
+func speedDistribution(model string, mean, stdDev, cutOut float64) []speedMass {
+	if stdDev <= 0 || mean <= 0 && model == WindWeibull {
+		return []speedMass{{math.Max(mean, 0), 1}}
+	}
+
+	var cdf func(v float64) float64
+	switch model {
+	case WindWeibull:
+		k := weibullShape(stdDev / mean)
+		scale := mean / math.Gamma(1+1/k)
+		cdf = func(v float64) float64 { return 1 - math.Exp(-math.Pow(v/scale, k)) }
+	default:
+		cdf = func(v float64) float64 { return normalCDF(v, mean, stdDev) }
+	}
+
+	top := math.Max(mean+10*stdDev, cutOut)
+	step := top / windSteps
+	masses := make([]speedMass, 0, windSteps+1)
+	masses = append(masses, speedMass{0, cdf(0)}) // Calm (and negative speeds for the normal model)
+	prev := cdf(0)
+	for i := 1; i <= windSteps; i++ {
+		v := float64(i) * step
+		next := cdf(v)
+		masses = append(masses, speedMass{v - step/2, next - prev})
+		prev = next
+	}
+	masses = append(masses, speedMass{top + step, 1 - prev})
+	return masses
+}
+
+// Weibull shape parameter giving the coefficient of variation cv, by bisection
+func weibullShape(cv float64) float64 {
+	variation := func(k float64) float64 {
+		g1 := math.Gamma(1 + 1/k)
+		return math.Sqrt(math.Gamma(1+2/k)/(g1*g1) - 1)
+	}
+	lo, hi := 0.2, 100.0
+	for i := 0; i < 60; i++ {
+		mid := (lo + hi) / 2
+		if variation(mid) > cv {
+			lo = mid
+		} else {
+			hi = mid
+		}
+	}
+	return (lo + hi) / 2
+}
+
+// Wind plant forecast for one day
+type WindInput struct {
+	Speed        []float64 // Forecast wind speed per interval, m/s
+	StdDevBefore []float64 // Wind speed error σ1, m/s
+	StdDevAfter  []float64 // Wind speed error σ2 after improvement, m/s
+	Curve        PowerCurve
+	Turbines     int
+	Model        string
+}
+
+// Result for one interval of the wind plant
+type WindInterval struct {
+	Start                     string
+	Speed                     float64 // m/s
+	Forecast                  float64 // MW, power curve at the forecast speed
+	Expected                  float64 // Expected power E[P(v)] under the current error σ1, MW
+	StdDevBefore, StdDevAfter float64 // Power error σ, MW
+	BandLower, BandUpper      float64
+	Before, After             Settlement
+}
+
+// Wind plant result: the same W1/W2 breakdown as for the solar plant
+type WindResult struct {
+	Turbines  int
+	Rated     float64 // Plant rated power, MW
+	Model     string
+	Tolerance Tolerance
+	Intervals []WindInterval
+	Before    Settlement
+	After     Settlement
+}
+
+// Settle one scenario: the power distribution comes from the speed distribution through the power curve
+func (in WindInput) settle(masses []speedMass, lower, upper, forecast, hours float64, pricing Pricing) Settlement {
+	var below, above float64
+	for _, m := range masses {
+		power := in.Curve.At(m.Speed) * float64(in.Turbines) / 1000
+		switch {
+		case power < lower:
+			below += m.P
+		case power > upper:
+			above += m.P
+		}
+	}
+	energy := forecast * hours
+	s := Settlement{InBand: energy * (1 - below - above), Surplus: energy * above, Deficit: energy * below}
+	s.Price(pricing)
+	return s
+}
+
+// Expected plant power and its standard deviation, MW
+func (in WindInput) powerStats(masses []speedMass) (float64, float64) {
+	mean, sq := 0.0, 0.0
+	for _, m := range masses {
+		power := in.Curve.At(m.Speed) * float64(in.Turbines) / 1000
+		mean += m.P * power
+		sq += m.P * power * power
+	}
+	return mean, math.Sqrt(math.Max(sq-mean*mean, 0))
+}
+
+// Settle every interval of the wind forecast. The scheduled power is the power curve at the
+// forecast speed, P(v̄), as nominated from a point speed forecast; with a nonlinear curve it
+// differs from the expected power E[P(v)], which is reported alongside.
+func calculateWind(in WindInput, tolerance Tolerance, pricing Pricing) WindResult {
+	res := WindResult{
+		Turbines:  in.Turbines,
+		Rated:     in.Curve.Rated() * float64(in.Turbines) / 1000,
+		Model:     in.Model,
+		Tolerance: tolerance,
+	}
+	hours := 24 / float64(len(in.Speed))
+	for i, v := range in.Speed {
+		cutOut := in.Curve.Speeds[len(in.Curve.Speeds)-1]
+		before := speedDistribution(in.Model, v, in.StdDevBefore[i], cutOut)
+		after := speedDistribution(in.Model, v, in.StdDevAfter[i], cutOut)
+		forecast := in.Curve.At(v) * float64(in.Turbines) / 1000
+		minutes := int(float64(i) * hours * 60)
+		interval := WindInterval{
+			Start:    fmt.Sprintf("%02d:%02d", minutes/60, minutes%60),
+			Speed:    v,
+			Forecast: forecast,
+		}
+		interval.Expected, interval.StdDevBefore = in.powerStats(before)
+		_, interval.StdDevAfter = in.powerStats(after)
+		interval.BandLower, interval.BandUpper = tolerance.Band(forecast, interval.StdDevAfter)
+		interval.Before = in.settle(before, interval.BandLower, interval.BandUpper, forecast, hours, pricing)
+		interval.After = in.settle(after, interval.BandLower, interval.BandUpper, forecast, hours, pricing)
+		res.Before.Add(interval.Before)
+		res.After.Add(interval.After)
+		res.Intervals = append(res.Intervals, interval)
+	}
+	return res
+}
+
+// Parse the wind form
+func parseWind(r *http.Request) (WindInput, error) {
+	in := WindInput{Model: r.FormValue("windModel")}
+	switch in.Model {
+	case "":
+		in.Model = WindNormal
+	case WindNormal, WindWeibull:
+	default:
+		return in, fmt.Errorf("unknown windModel %q", in.Model)
+	}
+
+	var err error
+	in.Speed, err = parseProfile(r.FormValue("windSpeed"))
+	if err != nil || len(in.Speed) == 0 {
+		return in, fmt.Errorf("Invalid input for windSpeed")
+	}
+	if len(in.Speed) == 1 {
+		in.Speed = repeat(in.Speed[0], 24)
+	}
+	if n := len(in.Speed); n != 24 && n != 96 {
+		return in, fmt.Errorf("Invalid input for windSpeed: expected 1, 24 or 96 values, got %d", n)
+	}
+	if in.StdDevBefore, err = parseProfileOrScalar(r.FormValue("windStdDevBefore"), len(in.Speed), 0); err != nil {
+		return in, fmt.Errorf("Invalid input for windStdDevBefore: %v", err)
+	}
+	if in.StdDevAfter, err = parseProfileOrScalar(r.FormValue("windStdDevAfter"), len(in.Speed), 0); err != nil {
+		return in, fmt.Errorf("Invalid input for windStdDevAfter: %v", err)
+	}
+	if in.Curve, err = parsePowerCurve(r.FormValue("powerCurve")); err != nil {
+		return in, fmt.Errorf("Invalid input for powerCurve: %v", err)
+	}
+	in.Turbines = 1
+	if v := r.FormValue("turbines"); v != "" {
+		in.Turbines, err = strconv.Atoi(v)
+		if err != nil || in.Turbines < 1 {
+			return in, fmt.Errorf("Invalid input for turbines")
+		}
+	}
+	return in, nil
+}
+
+// Handle the wind form
+func windCalculate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+
+	in, err := parseWind(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pricing, err := parsePricing(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	tolerance, err := parseTolerance(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	res := calculateWind(in, tolerance, pricing)
+	render(w, PageData{Values: formValues(r), Wind: &res})
+}
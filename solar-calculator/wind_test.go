@@ -0,0 +1,89 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+func TestPowerCurveAt(t *testing.T) {
+	tests := []struct{ v, want float64 }{
+		{0, 0}, {3, 0}, {3.5, 0}, {4.5, 95}, {8, 950}, {13.5, 2965}, {20, 3000}, {25, 3000}, {25.1, 0},
+	}
+	for _, tt := range tests {
+		approx(t, "P(v)", defaultPowerCurve.At(tt.v), tt.want, 1e-9)
+	}
+}
+
+func TestSpeedDistribution(t *testing.T) {
+	tests := []struct {
+		model        string
+		mean, stdDev float64
+	}{
+		{WindNormal, 8, 2},
+		{WindNormal, 1, 2},
+		{WindWeibull, 8, 4},
+		{WindWeibull, 0.5, 1.5},
+	}
+	for _, tt := range tests {
+		masses := speedDistribution(tt.model, tt.mean, tt.stdDev, 25)
+		total, mean := 0.0, 0.0
+		for _, m := range masses {
+			total += m.P
+			mean += m.P * math.Max(m.Speed, 0)
+		}
+		approx(t, tt.model+" total mass", total, 1, 1e-12)
+		if tt.model == WindWeibull {
+			approx(t, tt.model+" mean speed", mean, tt.mean, 0.01)
+		}
+		if last := masses[len(masses)-1]; last.Speed <= 25 || defaultPowerCurve.At(last.Speed) != 0 {
+			t.Errorf("%s %g/%g: tail mass at %g m/s, want above cut-out", tt.model, tt.mean, tt.stdDev, last.Speed)
+		}
+	}
+
+	// Heavy-tailed Weibull: mean + 10σ = 15.5 m/s is below the cut-out, so the mass between
+	// 15.5 and 25 m/s stays on the grid at rated power and only the mass above 25 m/s produces nothing
+	k := weibullShape(3)
+	scale := 0.5 / math.Gamma(1+1/k)
+	exceed := func(v float64) float64 { return math.Exp(-math.Pow(v/scale, k)) }
+	rated, stopped := 0.0, 0.0
+	for _, m := range speedDistribution(WindWeibull, 0.5, 1.5, 25) {
+		switch {
+		case m.Speed > 25:
+			stopped += m.P
+		case m.Speed > 15.5:
+			rated += m.P
+		}
+	}
+	approx(t, "mass 15.5–25 m/s", rated, exceed(15.5)-exceed(25), 1e-5)
+	approx(t, "mass above cut-out", stopped, exceed(25), 1e-9)
+}
+
+func TestCalculateWind(t *testing.T) {
+	pricing := Pricing{EnergyCost: 7, SurplusPrice: 7, DeficitPrice: 7, SurplusMultiplier: 1, DeficitMultiplier: 1}
+	in := WindInput{
+		Speed:        repeat(8, 24),
+		StdDevBefore: repeat(0, 24),
+		StdDevAfter:  repeat(0, 24),
+		Curve:        defaultPowerCurve,
+		Turbines:     2,
+		Model:        WindNormal,
+	}
+
+	// Perfect forecast: 2 × 0.95 MW all day, all of it in the band
+	res := calculateWind(in, Tolerance{Mode: TolerancePercent, Lower: 5, Upper: 5}, pricing)
+	approx(t, "rated", res.Rated, 6, 1e-9)
+	approx(t, "W1", res.Before.InBand, 45.6, 1e-9)
+	approx(t, "profit", res.Before.FinalProfit, 319.2, 1e-9)
+	approx(t, "E[P]", res.Intervals[0].Expected, 1.9, 1e-9)
+
+	// The curve is convex around 8 m/s, so the expected power exceeds P(v̄)
+	in.StdDevBefore, in.StdDevAfter = repeat(2, 24), repeat(1, 24)
+	res = calculateWind(in, Tolerance{Mode: TolerancePercent, Lower: 5, Upper: 5}, pricing)
+	first := res.Intervals[0]
+	if first.Expected <= first.Forecast {
+		t.Errorf("E[P] = %.4f, want above P(v̄) = %.4f", first.Expected, first.Forecast)
+	}
+	if first.StdDevAfter >= first.StdDevBefore || res.After.FinalProfit <= res.Before.FinalProfit {
+		t.Errorf("σ2 < σ1 should narrow the power error and raise the profit: %+v", first)
+	}
+}
@@ -0,0 +1,300 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	solarConstant  = 1367.0 // W/m²
+	stcIrradiance  = 1000.0 // W/m² at standard test conditions
+	stcTemperature = 25.0   // °C
+	noctIrradiance = 800.0  // W/m² at nominal operating cell temperature conditions
+	noctAmbient    = 20.0   // °C
+	dailyTempSwing = 5.0    // Amplitude of the clear-sky ambient temperature profile, °C
+)
+
+// PV plant and site for the yield model
+type PVPlant struct {
+	Latitude, Longitude float64 // Degrees, east positive
+	Timezone            float64 // Hours from UTC of the forecast timestamps
+	Tilt                float64 // Module tilt from horizontal, degrees
+	Azimuth             float64 // Module azimuth from north clockwise, degrees (180 = south)
+	Capacity            float64 // DC capacity at STC, MWp
+	TempCoeff           float64 // Power temperature coefficient, %/°C (negative)
+	NOCT                float64 // Nominal operating cell temperature, °C
+	InverterPower       float64 // AC rating, MW
+	InverterEfficiency  float64 // Fraction
+	Losses              float64 // Soiling, wiring, mismatch, fraction
+	Albedo              float64
+	AmbientTemp         float64 // Daily mean ambient temperature for the clear-sky model, °C
+}
+
+// Weather for one hour: from the clear-sky model or a TMY file
+type Weather struct {
+	GHI     float64 // Global horizontal irradiance, W/m²
+	Ambient float64 // °C
+}
+
+// Modelled hour
+type YieldHour struct {
+	Start     string
+	Elevation float64 // Sun elevation at mid-hour, degrees
+	GHI, POA  float64 // W/m²
+	CellTemp  float64 // °C
+	DC, AC    float64 // MW
+}
+
+// Yield of one day
+type YieldResult struct {
+	PVPlant
+	Date           string
+	Source         string // Description of the irradiance source
+	Hours          []YieldHour
+	Energy         float64 // MWh per day
+	Peak           float64 // MW
+	CapacityFactor float64
+	Clipped        float64 // MWh lost to the inverter limit
+}
+
+// Position of the sun (NOAA approximation): zenith cosine, azimuth from north clockwise in degrees,
+// and extraterrestrial irradiance on a horizontal-normal plane
+func sunPosition(lat, lon, tz float64, doy int, hour float64) (cosZenith, azimuth, extra float64) {
+	g := 2 * math.Pi / 365 * (float64(doy-1) + (hour-12)/24)
+	eqTime := 229.18 * (0.000075 + 0.001868*math.Cos(g) - 0.032077*math.Sin(g) -
+		0.014615*math.Cos(2*g) - 0.040849*math.Sin(2*g))
+	decl := 0.006918 - 0.399912*math.Cos(g) + 0.070257*math.Sin(g) - 0.006758*math.Cos(2*g) +
+		0.000907*math.Sin(2*g) - 0.002697*math.Cos(3*g) + 0.00148*math.Sin(3*g)
+
+	solarTime := hour*60 + eqTime + 4*lon - 60*tz // minutes
+	hourAngle := (solarTime/4 - 180) * math.Pi / 180
+	phi := lat * math.Pi / 180
+
+	cosZenith = math.Sin(phi)*math.Sin(decl) + math.Cos(phi)*math.Cos(decl)*math.Cos(hourAngle)
+	azimuth = 180 + math.Atan2(math.Sin(hourAngle), math.Cos(hourAngle)*math.Sin(phi)-math.Tan(decl)*math.Cos(phi))*180/math.Pi
+	extra = solarConstant * (1 + 0.033*math.Cos(2*math.Pi*float64(doy)/365))
+	return cosZenith, azimuth, extra
+}
+
+// Haurwitz clear-sky global horizontal irradiance
+func clearSkyGHI(cosZenith float64) float64 {
+	if cosZenith <= 0 {
+		return 0
+	}
+	return 1098 * cosZenith * math.Exp(-0.057/cosZenith)
+}
+
+// Erbs diffuse fraction of the global irradiance from the clearness index
+func diffuseFraction(kt float64) float64 {
+	switch {
+	case kt <= 0.22:
+		return 1 - 0.09*kt
+	case kt <= 0.8:
+		return 0.9511 - 0.1604*kt + 4.388*kt*kt - 16.638*kt*kt*kt + 12.336*kt*kt*kt*kt
+	default:
+		return 0.165
+	}
+}
+
+// Plane-of-array irradiance by the isotropic-sky (Liu–Jordan) transposition
+func (pv PVPlant) planeOfArray(ghi, cosZenith, sunAzimuth, extra float64) float64 {
+	if ghi <= 0 || cosZenith <= 0.01 {
+		return 0
+	}
+	kt := math.Min(ghi/(extra*cosZenith), 1)
+	dhi := ghi * diffuseFraction(kt)
+	dni := (ghi - dhi) / cosZenith
+
+	beta := pv.Tilt * math.Pi / 180
+	sinZenith := math.Sqrt(1 - cosZenith*cosZenith)
+	cosAOI := cosZenith*math.Cos(beta) + sinZenith*math.Sin(beta)*math.Cos((sunAzimuth-pv.Azimuth)*math.Pi/180)
+
+	beam := dni * math.Max(cosAOI, 0)
+	sky := dhi * (1 + math.Cos(beta)) / 2
+	ground := ghi * pv.Albedo * (1 - math.Cos(beta)) / 2
+	return beam + sky + ground
+}
+
+// Model the AC output of every hour of the day; weather is nil for the clear-sky model
+func (pv PVPlant) Yield(date time.Time, weather []Weather) YieldResult {
+	res := YieldResult{PVPlant: pv, Date: date.Format("2006-01-02"), Source: "модель ясного неба (Haurwitz)"}
+	if weather != nil {
+		res.Source = "типовий метеорологічний рік (TMY)"
+	}
+	doy := date.YearDay()
+
+	for h := 0; h < 24; h++ {
+		cosZenith, sunAzimuth, extra := sunPosition(pv.Latitude, pv.Longitude, pv.Timezone, doy, float64(h)+0.5)
+		w := Weather{
+			GHI:     clearSkyGHI(cosZenith),
+			Ambient: pv.AmbientTemp + dailyTempSwing*math.Cos(2*math.Pi*(float64(h)-15)/24),
+		}
+		if weather != nil {
+			w = weather[h]
+		}
+
+		hour := YieldHour{
+			Start:     fmt.Sprintf("%02d:00", h),
+			Elevation: 90 - math.Acos(math.Max(-1, math.Min(1, cosZenith)))*180/math.Pi,
+			GHI:       w.GHI,
+		}
+		hour.POA = pv.planeOfArray(w.GHI, cosZenith, sunAzimuth, extra)
+		hour.CellTemp = w.Ambient + (pv.NOCT-noctAmbient)/noctIrradiance*hour.POA
+		dc := pv.Capacity * hour.POA / stcIrradiance * (1 + pv.TempCoeff/100*(hour.CellTemp-stcTemperature)) * (1 - pv.Losses)
+		hour.DC = math.Max(dc, 0)
+		ac := hour.DC * pv.InverterEfficiency
+		hour.AC = math.Min(ac, pv.InverterPower)
+		res.Clipped += ac - hour.AC
+
+		res.Energy += hour.AC
+		res.Peak = math.Max(res.Peak, hour.AC)
+		res.Hours = append(res.Hours, hour)
+	}
+	if pv.InverterPower > 0 {
+		res.CapacityFactor = res.Energy / (24 * pv.InverterPower)
+	}
+	return res
+}
+
+// Form values pre-filled from the yield: the hourly AC output becomes the forecast profile
+func (res YieldResult) Prefill() map[string]string {
+	values := make([]string, len(res.Hours))
+	for i, h := range res.Hours {
+		values[i] = strconv.FormatFloat(h.AC, 'f', 4, 64)
+	}
+	return map[string]string{"forecastProfile": strings.Join(values, " "), "dailyPower": ""}
+}
+
+// Parse a TMY CSV with columns: timestamp, GHI (W/m²), ambient temperature (°C) and pick the
+// 24 hours of the requested day of the year (the TMY year itself is ignored)
+func parseTMY(r io.Reader, date time.Time) ([]Weather, error) {
+	records, number, err := readCSV(r)
+	if err != nil {
+		return nil, err
+	}
+	weather := make([]Weather, 24)
+	found := make([]bool, 24)
+	for i, rec := range records {
+		if len(rec) < 3 {
+			return nil, fmt.Errorf("line %d: expected timestamp, GHI, temperature", i+1)
+		}
+		t, err := parseTime(strings.TrimSpace(rec[0]))
+		if err != nil {
+			if i == 0 {
+				continue // header
+			}
+			return nil, fmt.Errorf("line %d: %v", i+1, err)
+		}
+		if t.Month() != date.Month() || t.Day() != date.Day() {
+			continue
+		}
+		ghi, err1 := number(rec[1])
+		temp, err2 := number(rec[2])
+		if err1 != nil || err2 != nil || ghi < 0 || !finite(ghi) || !finite(temp) {
+			return nil, fmt.Errorf("line %d: GHI and temperature must be numbers", i+1)
+		}
+		weather[t.Hour()] = Weather{ghi, temp}
+		found[t.Hour()] = true
+	}
+	for h, ok := range found {
+		if !ok {
+			return nil, fmt.Errorf("no data for %s %02d:00", date.Format("01-02"), h)
+		}
+	}
+	return weather, nil
+}
+
+// Parse the plant fields of the yield form
+func parsePVPlant(r *http.Request) (PVPlant, error) {
+	pv := PVPlant{
+		Latitude: 50.45, Longitude: 30.52, Timezone: 2, Tilt: 30, Azimuth: 180,
+		TempCoeff: -0.4, NOCT: 45, InverterEfficiency: 0.96, Losses: 0.14, Albedo: 0.2, AmbientTemp: 20,
+	}
+	fields := []struct {
+		name     string
+		v        *float64
+		scale    float64
+		min, max float64
+	}{
+		{"latitude", &pv.Latitude, 1, -90, 90},
+		{"longitude", &pv.Longitude, 1, -180, 180},
+		{"timezone", &pv.Timezone, 1, -12, 14},
+		{"tilt", &pv.Tilt, 1, 0, 90},
+		{"azimuth", &pv.Azimuth, 1, 0, 360},
+		{"pvCapacity", &pv.Capacity, 1, 0, math.Inf(1)},
+		{"tempCoeff", &pv.TempCoeff, 1, -2, 0},
+		{"noct", &pv.NOCT, 1, 20, 80},
+		{"inverterPower", &pv.InverterPower, 1, 0, math.Inf(1)},
+		{"inverterEfficiency", &pv.InverterEfficiency, 0.01, 0, 1},
+		{"pvLosses", &pv.Losses, 0.01, 0, 1},
+		{"albedo", &pv.Albedo, 1, 0, 1},
+		{"ambientTemp", &pv.AmbientTemp, 1, -50, 60},
+	}
+	for _, f := range fields {
+		s := r.FormValue(f.name)
+		if s == "" {
+			continue
+		}
+		x, err := strconv.ParseFloat(s, 64)
+		if err != nil || !finite(x) || x*f.scale < f.min || x*f.scale > f.max {
+			return pv, fmt.Errorf("Invalid input for %s", f.name)
+		}
+		*f.v = x * f.scale
+	}
+	if pv.Capacity <= 0 {
+		return pv, fmt.Errorf("Invalid input for pvCapacity")
+	}
+	if pv.InverterPower == 0 {
+		pv.InverterPower = pv.Capacity
+	}
+	return pv, nil
+}
+
+// Handle the yield form: model the day and pre-fill the forecast profile of the calculation form
+func pvYield(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Invalid request method", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseMultipartForm(maxHistorySize); err != nil {
+		http.Error(w, "Invalid upload", http.StatusBadRequest)
+		return
+	}
+
+	pv, err := parsePVPlant(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	date := time.Now()
+	if v := r.FormValue("date"); v != "" {
+		date, err = time.Parse("2006-01-02", v)
+		if err != nil {
+			http.Error(w, "Invalid input for date", http.StatusBadRequest)
+			return
+		}
+	}
+
+	var weather []Weather
+	if file, _, err := r.FormFile("tmy"); err == nil {
+		defer file.Close()
+		weather, err = parseTMY(file, date)
+		if err != nil {
+			http.Error(w, "Invalid TMY file: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	}
+
+	res := pv.Yield(date, weather)
+	values := formValues(r)
+	for k, v := range res.Prefill() {
+		values[k] = v
+	}
+	render(w, PageData{Values: values, Yield: &res})
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Kyiv, 30° south-facing modules, the defaults of the yield form
+var kyivPlant = PVPlant{
+	Latitude: 50.45, Longitude: 30.52, Timezone: 2, Tilt: 30, Azimuth: 180, Capacity: 1,
+	TempCoeff: -0.4, NOCT: 45, InverterPower: 1, InverterEfficiency: 0.96, Losses: 0.14, Albedo: 0.2, AmbientTemp: 20,
+}
+
+func TestSunPosition(t *testing.T) {
+	elevation := func(cosZenith float64) float64 { return 90 - math.Acos(cosZenith)*180/math.Pi }
+
+	// Kyiv at noon on the summer solstice: 90° - 50.45° + 23.44°, just before the meridian
+	cosZenith, azimuth, extra := sunPosition(50.45, 30.52, 2, 172, 12)
+	approx(t, "solstice elevation", elevation(cosZenith), 63.001649, 1e-6)
+	approx(t, "solstice azimuth", azimuth, 180.379802, 1e-6)
+	approx(t, "extraterrestrial", extra, 1367*(1+0.033*math.Cos(2*math.Pi*172/365)), 1e-9)
+
+	// Equator at the March equinox: the sun is nearly overhead
+	cosZenith, _, _ = sunPosition(0, 0, 0, 80, 12)
+	approx(t, "equinox elevation", elevation(cosZenith), 88.034371, 1e-6)
+
+	if cosZenith, _, _ := sunPosition(50.45, 30.52, 2, 172, 0.5); cosZenith >= 0 {
+		t.Errorf("sun above the horizon at midnight: cos θz = %v", cosZenith)
+	}
+}
+
+func TestClearSkyAndDiffuse(t *testing.T) {
+	tests := []struct {
+		name      string
+		got, want float64
+	}{
+		{"GHI at zenith", clearSkyGHI(1), 1037.164288},
+		{"GHI at 60°", clearSkyGHI(0.5), 489.849618},
+		{"GHI below horizon", clearSkyGHI(-0.2), 0},
+		{"diffuse, overcast", diffuseFraction(0.1), 0.991},
+		{"diffuse, partly cloudy", diffuseFraction(0.5), 0.65915},
+		{"diffuse, clear", diffuseFraction(0.9), 0.165},
+	}
+	for _, tt := range tests {
+		approx(t, tt.name, tt.got, tt.want, 1e-6)
+	}
+}
+
+func TestYieldClearSky(t *testing.T) {
+	tests := []struct {
+		name               string
+		date               string
+		inverter           float64
+		energy, peak, clip float64
+		capacityFactor     float64
+	}{
+		{"summer", "2025-06-21", 1, 6.494530, 0.733762, 0, 0.270605},
+		{"winter", "2025-12-21", 1, 1.835765, 0.383390, 0, 0.076490},
+		{"summer, undersized inverter", "2025-06-21", 0.5, 5.373074, 0.5, 1.121456, 0.447756},
+	}
+	for _, tt := range tests {
+		date, _ := time.Parse("2006-01-02", tt.date)
+		pv := kyivPlant
+		pv.InverterPower = tt.inverter
+		res := pv.Yield(date, nil)
+
+		approx(t, tt.name+" energy", res.Energy, tt.energy, 1e-6)
+		approx(t, tt.name+" peak", res.Peak, tt.peak, 1e-6)
+		approx(t, tt.name+" clipped", res.Clipped, tt.clip, 1e-6)
+		approx(t, tt.name+" capacity factor", res.CapacityFactor, tt.capacityFactor, 1e-6)
+		if len(res.Hours) != 24 || res.Hours[0].AC != 0 || res.Hours[23].AC != 0 {
+			t.Errorf("%s: expected 24 hours with no output at night", tt.name)
+		}
+	}
+
+	// The noon hour in detail
+	date, _ := time.Parse("2006-01-02", "2025-06-21")
+	noon := kyivPlant.Yield(date, nil).Hours[12]
+	approx(t, "noon GHI", noon.GHI, 911.965854, 1e-5)
+	approx(t, "noon POA", noon.POA, 1004.198820, 1e-5)
+	approx(t, "noon cell temperature", noon.CellTemp, 54.916747, 1e-5)
+	approx(t, "noon AC", noon.AC, 0.729855, 1e-6)
+}
+
+// tmyDay builds a TMY CSV: sunnyHour of 21 June has 800 W/m² at 25 °C, the rest is dark at 10 °C; skip is left out
+func tmyDay(sunnyHour, skip int) string {
+	var b strings.Builder
+	b.WriteString("time,ghi,temp\n")
+	b.WriteString("2019-06-20 12:00,900,30\n") // Another day is ignored
+	for h := 0; h < 24; h++ {
+		switch h {
+		case skip:
+		case sunnyHour:
+			fmt.Fprintf(&b, "2019-06-21 %02d:00,800,25\n", h)
+		default:
+			fmt.Fprintf(&b, "2019-06-21 %02d:00,0,10\n", h)
+		}
+	}
+	return b.String()
+}
+
+func TestYieldTMY(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2025-06-21")
+	weather, err := parseTMY(strings.NewReader(tmyDay(12, -1)), date)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if weather[12] != (Weather{800, 25}) || weather[11] != (Weather{0, 10}) {
+		t.Errorf("weather = %v", weather)
+	}
+
+	res := kyivPlant.Yield(date, weather)
+	approx(t, "energy", res.Energy, 0.637084, 1e-6)
+	approx(t, "peak", res.Peak, res.Energy, 1e-12)
+
+	if _, err := parseTMY(strings.NewReader(tmyDay(12, 5)), date); err == nil || !strings.Contains(err.Error(), "05:00") {
+		t.Errorf("missing hour: error %v", err)
+	}
+}
+
+func TestYieldPrefill(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2025-06-21")
+	values := kyivPlant.Yield(date, nil).Prefill()
+	profile, err := parseProfile(values["forecastProfile"])
+	if err != nil || len(profile) != 24 {
+		t.Fatalf("forecastProfile %q: %v", values["forecastProfile"], err)
+	}
+	approx(t, "noon forecast", profile[12], 0.7299, 1e-9)
+	if values["dailyPower"] != "" {
+		t.Errorf("dailyPower = %q, want it cleared", values["dailyPower"])
+	}
+}
+
+func TestParsePVPlantRejectsNonFinite(t *testing.T) {
+	for _, field := range []string{"latitude", "tilt", "azimuth", "pvCapacity", "inverterPower", "ambientTemp"} {
+		for _, v := range []string{"NaN", "+Inf"} {
+			form := url.Values{"pvCapacity": {"1"}}
+			form.Set(field, v)
+			if _, err := parsePVPlant(postForm("/yield", form)); err == nil {
+				t.Errorf("%s=%s accepted", field, v)
+			}
+		}
+	}
+	if pv, err := parsePVPlant(postForm("/yield", url.Values{"pvCapacity": {"2"}})); err != nil || pv.InverterPower != 2 {
+		t.Errorf("defaults: %+v, %v", pv, err)
+	}
+}
+
+func TestParseTMYRejectsNonFinite(t *testing.T) {
+	date, _ := time.Parse("2006-01-02", "2025-06-21")
+	for _, row := range []string{"2019-06-21 12:00,NaN,25", "2019-06-21 12:00,800,Inf"} {
+		csv := strings.Replace(tmyDay(12, -1), "2019-06-21 12:00,800,25", row, 1)
+		if _, err := parseTMY(strings.NewReader(csv), date); err == nil {
+			t.Errorf("%q accepted", row)
+		}
+	}
+}